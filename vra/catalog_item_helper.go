@@ -7,6 +7,7 @@ package vra
 import "encoding/json"
 
 const (
+	CatalogItemBlueprintTypeID   string = "com.vmw.blueprint"
 	CatalogItemVMImageTypeID     string = "com.vmw.vmimage"
 	CatalogItemVroWorkflowTypeID string = "com.vmw.vro.workflow"
 )
@@ -5,6 +5,8 @@
 package vra
 
 import (
+	"bytes"
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -13,6 +15,7 @@ import (
 	"log"
 	"net/http"
 	neturl "net/url"
+	"os"
 	"regexp"
 	"strings"
 	"sync"
@@ -22,10 +25,10 @@ import (
 	httptransport "github.com/go-openapi/runtime/client"
 	"github.com/go-openapi/strfmt"
 	"github.com/hashicorp/go-version"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/logging"
 	"github.com/vmware/vra-sdk-go/pkg/client"
 	"github.com/vmware/vra-sdk-go/pkg/client/login"
 	"github.com/vmware/vra-sdk-go/pkg/models"
+	"golang.org/x/net/http/httpproxy"
 )
 
 // API Versions
@@ -37,6 +40,13 @@ const (
 
 const DefaultDollarTop = 1000
 
+// Delay before the first poll and minimum interval between the polls of the asynchronous operations. They are
+// shortened by the tests against the fake vRA API.
+var (
+	pollDelay      = 5 * time.Second
+	pollMinTimeout = 5 * time.Second
+)
+
 type ReauthTimeout struct {
 	mu      sync.Mutex
 	seconds time.Duration
@@ -73,27 +83,38 @@ func (t *ReauthTimeout) ShouldReload() bool {
 }
 
 type ReauthorizeRuntime struct {
-	origClient   httptransport.Runtime
-	url          string
-	organization string
-	refreshToken string
-	insecure     bool
-	reauthtimer  *ReauthTimeout
+	origClient       httptransport.Runtime
+	url              string
+	organization     string
+	refreshToken     string
+	transportOptions TransportOptions
+	reauthtimer      *ReauthTimeout
+	tokenCache       *tokenCache
+	credentials      *loginCredentials
+
+	// mu guards the refresh token and the access token, which the operations run in parallel by Terraform renew
+	// concurrently. The generation counts the renewals, so that an access token is renewed once when it expires.
+	mu         sync.Mutex
+	generation int
+}
+
+// loginCredentials are the user credentials used to log in through the identity service of vRA 8.x
+type loginCredentials struct {
+	username string
+	password string
+	domain   string
 }
 
 // Submit implements the ClientTransport interface as a wrapper to retry a 401 with a new token.
+// Transient errors (429, 502, 503, 504) are retried by the underlying http transport, see retryTransport.
 func (r *ReauthorizeRuntime) Submit(operation *runtime.ClientOperation) (interface{}, error) {
-	if r.reauthtimer.ShouldReload() {
-		log.Printf("Reauthorize timer expired, generating a new access token")
-		token, tokenErr := getToken(r.url, r.organization, r.refreshToken, r.insecure)
-		if tokenErr != nil {
-			return nil, tokenErr
-		}
-
-		// Fix up the Authorization header with the new token
-		r.origClient.DefaultAuthentication = httptransport.APIKeyAuth("Authorization", "header", "Bearer "+token)
+	auth, generation, tokenErr := r.authentication()
+	if tokenErr != nil {
+		return nil, tokenErr
 	}
 
+	// The Authorization is set on the operation, as the default one of the runtime may be renewed concurrently
+	operation.AuthInfo = auth
 	result, err := r.origClient.Submit(operation)
 	if err == nil {
 		return result, err
@@ -106,30 +127,146 @@ func (r *ReauthorizeRuntime) Submit(operation *runtime.ClientOperation) (interfa
 
 	// We have a 401 with a refresh token, let's try refreshing once and try again
 	log.Printf("Response back was a 401, trying again with new access token")
-	token, tokenErr := getToken(r.url, r.organization, r.refreshToken, r.insecure)
+	auth, tokenErr = r.reauthorizeAfter(generation)
 	if tokenErr != nil {
 		return result, err
 	}
 
-	// Fix up the Authorization header with the new token and resubmit the request
-	r.origClient.DefaultAuthentication = httptransport.APIKeyAuth("Authorization", "header", "Bearer "+token)
+	// Resubmit the request with the new token
+	operation.AuthInfo = auth
 	result, err = r.origClient.Submit(operation)
 	return result, err
 }
 
+// authentication returns the Authorization of the current access token and its generation, once renewed if the
+// reauthorize timer expired.
+func (r *ReauthorizeRuntime) authentication() (runtime.ClientAuthInfoWriter, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.reauthtimer.ShouldReload() {
+		log.Printf("Reauthorize timer expired, generating a new access token")
+		if err := r.reauthorize(); err != nil {
+			return nil, 0, err
+		}
+	}
+	return r.origClient.DefaultAuthentication, r.generation, nil
+}
+
+// reauthorizeAfter renews the access token of the generation rejected with a 401 and returns the Authorization of the
+// new one. The access token is renewed once, the operations rejected concurrently use the token renewed by the first.
+func (r *ReauthorizeRuntime) reauthorizeAfter(generation int) (runtime.ClientAuthInfoWriter, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.generation == generation {
+		if err := r.reauthorize(); err != nil {
+			return nil, err
+		}
+	}
+	return r.origClient.DefaultAuthentication, nil
+}
+
+// reauthorize generates a new access token, stores it in the token cache and fixes up the Authorization header with it.
+// When the runtime was created from user credentials, a failure to use the refresh token leads to a new login. Must be
+// called with the lock held.
+func (r *ReauthorizeRuntime) reauthorize() error {
+	token, err := getToken(r.url, r.organization, r.refreshToken, r.transportOptions)
+	if err != nil && r.credentials != nil {
+		log.Printf("Unable to generate a new access token from the refresh token, logging in again")
+		refreshToken, loginErr := getRefreshTokenFromCredentials(r.url, r.credentials, r.transportOptions)
+		if loginErr != nil {
+			return loginErr
+		}
+		r.refreshToken = refreshToken
+		token, err = getToken(r.url, r.organization, r.refreshToken, r.transportOptions)
+	}
+	if err != nil {
+		return err
+	}
+	r.tokenCache.put(r.url, r.organization, r.refreshToken, token)
+
+	r.origClient.DefaultAuthentication = httptransport.APIKeyAuth("Authorization", "header", "Bearer "+token)
+	r.generation++
+	return nil
+}
+
 // Client the VRA Client
 type Client struct {
-	url       string
-	apiClient *client.API
+	url              string
+	organization     string
+	transportOptions TransportOptions
+	apiClient        *client.API
+
+	// The product of the appliance, detected on first use
+	productMu sync.Mutex
+	product   *productInfo
+}
+
+// productInfo returns the product of the appliance the client is connected to.
+func (c *Client) productInfo() (*productInfo, error) {
+	c.productMu.Lock()
+	defer c.productMu.Unlock()
+
+	if c.product == nil {
+		info, err := getProductInfo(c.url, c.transportOptions)
+		if err != nil {
+			return nil, err
+		}
+		c.product = info
+	}
+	return c.product, nil
 }
 
 // NewClientFromRefreshToken configures and returns a VRA "Client" struct using "refresh_token" from provider config
-func NewClientFromRefreshToken(url, organization, refreshToken string, insecure bool, reauth string, apiTimeout int) (interface{}, error) {
-	token, err := getToken(url, organization, refreshToken, insecure)
+func NewClientFromRefreshToken(url, organization, refreshToken string, transportOptions TransportOptions, reauth string, apiTimeout int, retryOptions RetryOptions, logHTTPBody bool, tokenCacheDir string) (interface{}, error) {
+	cache := newTokenCache(tokenCacheDir)
+	token, ok := cache.get(url, organization, refreshToken)
+	if !ok {
+		var err error
+		token, err = getToken(url, organization, refreshToken, transportOptions)
+		if err != nil {
+			return "", err
+		}
+		cache.put(url, organization, refreshToken, token)
+	}
+
+	apiClient, err := getAPIClient(url, token, transportOptions, apiTimeout, retryOptions, logHTTPBody)
 	if err != nil {
 		return "", err
 	}
-	apiClient, err := getAPIClient(url, token, insecure, apiTimeout)
+
+	t := apiClient.Transport.(*httptransport.Runtime)
+	reautDuration, err := time.ParseDuration(reauth)
+
+	if err != nil {
+		return "", err
+	}
+	apiClient.SetTransport(&ReauthorizeRuntime{
+		origClient:       *t,
+		url:              url,
+		organization:     organization,
+		refreshToken:     refreshToken,
+		transportOptions: transportOptions,
+		reauthtimer:      InitializeTimeout(reautDuration),
+		tokenCache:       cache,
+	})
+
+	return &Client{url: url, organization: organization, transportOptions: transportOptions, apiClient: apiClient}, nil
+}
+
+// NewClientFromCredentials configures and returns a VRA "Client" struct using "username", "password" and "domain" from provider config
+func NewClientFromCredentials(url, username, password, domain string, transportOptions TransportOptions, reauth string, apiTimeout int, retryOptions RetryOptions, logHTTPBody bool) (interface{}, error) {
+	credentials := &loginCredentials{username: username, password: password, domain: domain}
+	refreshToken, err := getRefreshTokenFromCredentials(url, credentials, transportOptions)
+	if err != nil {
+		return "", err
+	}
+	token, err := getVRAToken(url, refreshToken, transportOptions)
+	if err != nil {
+		return "", err
+	}
+	apiClient, err := getAPIClient(url, token, transportOptions, apiTimeout, retryOptions, logHTTPBody)
 	if err != nil {
 		return "", err
 	}
@@ -140,96 +277,183 @@ func NewClientFromRefreshToken(url, organization, refreshToken string, insecure
 	if err != nil {
 		return "", err
 	}
-	apiClient.SetTransport(&ReauthorizeRuntime{*t, url, organization, refreshToken, insecure, InitializeTimeout(reautDuration)})
+	apiClient.SetTransport(&ReauthorizeRuntime{
+		origClient:       *t,
+		url:              url,
+		refreshToken:     refreshToken,
+		transportOptions: transportOptions,
+		reauthtimer:      InitializeTimeout(reautDuration),
+		credentials:      credentials,
+	})
 
-	return &Client{url, apiClient}, nil
+	return &Client{url: url, transportOptions: transportOptions, apiClient: apiClient}, nil
 }
 
 // NewClientFromAccessToken configures and returns a VRA "Client" struct using "access_token" from provider config
-func NewClientFromAccessToken(url, accessToken string, insecure bool, apiTimeout int) (interface{}, error) {
-	apiClient, err := getAPIClient(url, accessToken, insecure, apiTimeout)
+func NewClientFromAccessToken(url, organization, accessToken string, transportOptions TransportOptions, apiTimeout int, retryOptions RetryOptions, logHTTPBody bool) (interface{}, error) {
+	apiClient, err := getAPIClient(url, accessToken, transportOptions, apiTimeout, retryOptions, logHTTPBody)
 	if err != nil {
 		return "", err
 	}
-	return &Client{url, apiClient}, nil
+	return &Client{url: url, organization: organization, transportOptions: transportOptions, apiClient: apiClient}, nil
 }
 
-func getToken(url, organization, refreshToken string, insecure bool) (string, error) {
-	isVCFA, err := isVCFA(url, insecure)
+func getToken(url, organization, refreshToken string, transportOptions TransportOptions) (string, error) {
+	isVCFA, err := isVCFA(url, transportOptions)
 	if err != nil {
 		return "", fmt.Errorf("error determining whether vRA or VCFA: %s", err)
 	}
 	if isVCFA {
 		if organization == "" {
 			return "", errors.New("organization is required for VCFA")
-		} else if organization == "system" {
-			return "", errors.New("system organization is not allowed")
 		}
-		return getVCFAToken(url, organization, refreshToken, insecure)
+		return getVCFAToken(url, organization, refreshToken, transportOptions)
+	}
+	return getVRAToken(url, refreshToken, transportOptions)
+}
+
+// Products detected from the application version of the appliance
+const (
+	ProductVRA  = "vRA"
+	ProductVCFA = "VCFA"
+)
+
+// productVersionRegexp matches the version of the product in the application version, followed by the build number if any
+var productVersionRegexp = regexp.MustCompile(`v?(\d+\.\d+\.\d+\.\d+)(?:[-.\s]+(?:\(?build[\s:]*)?(\d+))?`)
+
+// productInfo describes the product of the appliance. The version and build are empty when they cannot be detected.
+type productInfo struct {
+	product string
+	version string
+	build   string
+}
+
+func isVCFA(url string, transportOptions TransportOptions) (bool, error) {
+	info, err := getProductInfo(url, transportOptions)
+	if err != nil {
+		return false, err
 	}
-	return getVRAToken(url, refreshToken, insecure)
+	return info.product == ProductVCFA, nil
 }
 
-func isVCFA(url string, insecure bool) (bool, error) {
+// getProductInfo detects the product and its version from the application version of /automation/config.json.
+// VCF Automation is version 9.0 and later, vRA 8.x does not always expose its version.
+func getProductInfo(url string, transportOptions TransportOptions) (*productInfo, error) {
 	parsedURL, err := neturl.Parse(url)
 	if err != nil {
-		return false, fmt.Errorf("error parsing the URL %s: %s", url, err)
+		return nil, fmt.Errorf("error parsing the URL %s: %s", url, err)
 	}
-	transport, err := createTransport(insecure)
+	transport, err := createTransport(transportOptions)
 	if err != nil {
-		return false, fmt.Errorf("error creating an http transport: %s", err)
+		return nil, fmt.Errorf("error creating an http transport: %s", err)
 	}
 	client := &http.Client{Transport: transport}
 	response, err := client.Get(fmt.Sprintf("%s://%s/automation/config.json", parsedURL.Scheme, parsedURL.Host))
 	if err != nil {
-		return false, fmt.Errorf("error retrieving the configuration from url %s: %s", url, err)
+		return nil, fmt.Errorf("error retrieving the configuration from url %s: %s", url, err)
 	}
+	defer response.Body.Close()
 	if response.StatusCode != http.StatusOK {
 		if response.StatusCode == http.StatusNotFound {
-			return false, nil
+			return &productInfo{product: ProductVRA}, nil
 		}
-		return false, fmt.Errorf("error retrieving the configuration from url %s, http response code is %d", url, response.StatusCode)
+		return nil, fmt.Errorf("error retrieving the configuration from url %s, http response code is %d", url, response.StatusCode)
 	}
-	defer response.Body.Close()
 	body, err := io.ReadAll(response.Body)
 	if err != nil {
-		return false, fmt.Errorf("error reading the http response body from url %s: %s", url, err)
+		return nil, fmt.Errorf("error reading the http response body from url %s: %s", url, err)
 	}
 	var config map[string]interface{}
 	if err := json.Unmarshal(body, &config); err != nil {
-		return false, fmt.Errorf("error unmarshalling the configuration from url %s: %s", url, err)
+		return nil, fmt.Errorf("error unmarshalling the configuration from url %s: %s", url, err)
 	}
+	info := &productInfo{product: ProductVRA}
 	applicationVersion, ok := config["applicationVersion"]
 	if ok {
 		productName, err := base64.StdEncoding.DecodeString(applicationVersion.(string))
 		if err != nil {
-			return false, fmt.Errorf("error decoding the application version %s: %s", applicationVersion, err)
+			return nil, fmt.Errorf("error decoding the application version %s: %s", applicationVersion, err)
 		}
-		re := regexp.MustCompile(`v?(\d+\.\d+\.\d+\.\d+)`)
-		match := re.FindStringSubmatch(string(productName))
+		match := productVersionRegexp.FindStringSubmatch(string(productName))
 		if match != nil {
 			productVersion, err := version.NewVersion(match[1])
 			if err != nil {
-				return false, fmt.Errorf("error parsing the application version %s: %s", match[1], err)
+				return nil, fmt.Errorf("error parsing the application version %s: %s", match[1], err)
 			}
+			info.version, info.build = match[1], match[2]
 			vcfaVersion, _ := version.NewVersion("9.0.0.0")
 			if productVersion.GreaterThanOrEqual(vcfaVersion) {
-				return true, nil
+				info.product = ProductVCFA
 			}
 		}
 	}
-	return false, nil
+	return info, nil
+}
+
+// Retrieve a refresh token for vRA 8.x instances by logging in with the user credentials
+func getRefreshTokenFromCredentials(url string, credentials *loginCredentials, transportOptions TransportOptions) (string, error) {
+	isVCFA, err := isVCFA(url, transportOptions)
+	if err != nil {
+		return "", fmt.Errorf("error determining whether vRA or VCFA: %s", err)
+	}
+	if isVCFA {
+		return "", errors.New("username and password authentication is not supported for VCFA, use refresh_token instead")
+	}
+
+	parsedURL, err := neturl.Parse(url)
+	if err != nil {
+		return "", fmt.Errorf("error parsing the URL %s: %s", url, err)
+	}
+	transport, err := createTransport(transportOptions)
+	if err != nil {
+		return "", fmt.Errorf("error creating an http transport: %s", err)
+	}
+	client := &http.Client{Transport: transport}
+
+	loginSpecification := map[string]string{
+		"username": credentials.username,
+		"password": credentials.password,
+	}
+	if credentials.domain != "" {
+		loginSpecification["domain"] = credentials.domain
+	}
+	data, err := json.Marshal(loginSpecification)
+	if err != nil {
+		return "", fmt.Errorf("error marshalling the login specification: %s", err)
+	}
+
+	response, err := client.Post(fmt.Sprintf("%s://%s/csp/gateway/am/api/login?access_token", parsedURL.Scheme, parsedURL.Host), "application/json", bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("error logging in as %s: %s", credentials.username, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("error logging in as %s, http response code is %d", credentials.username, response.StatusCode)
+	}
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return "", fmt.Errorf("error reading the http response body: %s", err)
+	}
+	var tokenData map[string]interface{}
+	if err := json.Unmarshal(body, &tokenData); err != nil {
+		return "", fmt.Errorf("error unmarshalling the token data: %s", err)
+	}
+	refreshToken, ok := tokenData["refresh_token"].(string)
+	if ok && refreshToken != "" {
+		return refreshToken, nil
+	}
+	return "", errors.New("Unable to obtain a refresh token")
 }
 
 // Retrieve the access token for vRA 8.x instances
-func getVRAToken(url, refreshToken string, insecure bool) (string, error) {
+func getVRAToken(url, refreshToken string, transportOptions TransportOptions) (string, error) {
 	parsedURL, err := neturl.Parse(url)
 	if err != nil {
 		return "", err
 	}
 	transport := httptransport.New(parsedURL.Host, parsedURL.Path, nil)
 	transport.SetDebug(false)
-	transport.Transport, err = createTransport(insecure)
+	transport.Transport, err = createTransport(transportOptions)
 	if err != nil {
 		return "", err
 	}
@@ -249,12 +473,12 @@ func getVRAToken(url, refreshToken string, insecure bool) (string, error) {
 }
 
 // Retrieve the access token for VCFA 9.x instances
-func getVCFAToken(url, org string, refreshToken string, insecure bool) (string, error) {
+func getVCFAToken(url, org string, refreshToken string, transportOptions TransportOptions) (string, error) {
 	parsedURL, err := neturl.Parse(url)
 	if err != nil {
 		return "", fmt.Errorf("error parsing the URL %s: %s", url, err)
 	}
-	transport, err := createTransport(insecure)
+	transport, err := createTransport(transportOptions)
 	if err != nil {
 		return "", fmt.Errorf("error creating an http transport: %s", err)
 	}
@@ -264,8 +488,9 @@ func getVCFAToken(url, org string, refreshToken string, insecure bool) (string,
 	data.Set("grant_type", "refresh_token")
 	data.Set("refresh_token", refreshToken)
 
+	// The provider organization authenticates against the provider endpoint
 	tenant := "tenant/" + org
-	if strings.EqualFold(org, "system") {
+	if isProviderOrganization(org) {
 		tenant = "provider"
 	}
 	response, err := client.Post(fmt.Sprintf("%s://%s/tm/oauth/%s/token", parsedURL.Scheme, parsedURL.Host, tenant), "application/x-www-form-urlencoded", strings.NewReader(data.Encode()))
@@ -291,12 +516,16 @@ func getVCFAToken(url, org string, refreshToken string, insecure bool) (string,
 	return "", errors.New("Unable to obtain an access token")
 }
 
-// SwaggerLogger is the interface into the swagger logging facility which logs http traffic
-type SwaggerLogger struct{}
+// SwaggerLogger is the interface into the swagger logging facility which logs http traffic.
+// Credentials are redacted from the logged traffic.
+type SwaggerLogger struct {
+	// OmitBody leaves the request and response bodies out of the logs
+	OmitBody bool
+}
 
 // Printf is a swagger debug Printf
-func (SwaggerLogger) Printf(format string, args ...interface{}) {
-	s := fmt.Sprintf(format, args...)
+func (l SwaggerLogger) Printf(format string, args ...interface{}) {
+	s := redactHTTPLog(fmt.Sprintf(format, args...), l.OmitBody)
 	// Handle and split mixed "\r\n" and "\n"
 	lines := strings.Split(strings.Replace(s, "\r\n", "\n", -1), "\n")
 
@@ -306,8 +535,8 @@ func (SwaggerLogger) Printf(format string, args ...interface{}) {
 }
 
 // Debugf is a swagger debug logger
-func (SwaggerLogger) Debugf(format string, args ...interface{}) {
-	s := fmt.Sprintf(format, args...)
+func (l SwaggerLogger) Debugf(format string, args ...interface{}) {
+	s := redactHTTPLog(fmt.Sprintf(format, args...), l.OmitBody)
 	// Handle and split mixed "\r\n" and "\n"
 	lines := strings.Split(strings.Replace(s, "\r\n", "\n", -1), "\n")
 
@@ -316,21 +545,112 @@ func (SwaggerLogger) Debugf(format string, args ...interface{}) {
 	}
 }
 
-func createTransport(insecure bool) (http.RoundTripper, error) {
-	cfg, err := httptransport.TLSClientAuth(httptransport.TLSClientOptions{
-		InsecureSkipVerify: insecure,
-	})
+// TransportOptions configures the TLS and proxy settings of the http transport used for all API calls
+type TransportOptions struct {
+	Insecure       bool
+	CAFile         string
+	CAPEM          string
+	ClientCertFile string
+	ClientKeyFile  string
+	ProxyURL       string
+	ProxyUsername  string
+	ProxyPassword  string
+	NoProxy        string
+}
+
+// tlsClientOptions converts the transport options into the TLS client options of the swagger runtime.
+// Custom CA certificates are trusted in addition to the system trust store.
+func (o TransportOptions) tlsClientOptions() (httptransport.TLSClientOptions, error) {
+	opts := httptransport.TLSClientOptions{
+		InsecureSkipVerify: o.Insecure,
+		Certificate:        o.ClientCertFile,
+		Key:                o.ClientKeyFile,
+	}
+
+	if o.CAFile == "" && o.CAPEM == "" {
+		return opts, nil
+	}
+
+	caCertPool, err := x509.SystemCertPool()
+	if err != nil || caCertPool == nil {
+		caCertPool = x509.NewCertPool()
+	}
+
+	if o.CAFile != "" {
+		caCert, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return opts, fmt.Errorf("error reading the CA file %s: %s", o.CAFile, err)
+		}
+		if !caCertPool.AppendCertsFromPEM(caCert) {
+			return opts, fmt.Errorf("the CA file %s does not contain any valid PEM encoded certificate", o.CAFile)
+		}
+	}
+
+	if o.CAPEM != "" {
+		if !caCertPool.AppendCertsFromPEM([]byte(o.CAPEM)) {
+			return opts, errors.New("the CA PEM does not contain any valid PEM encoded certificate")
+		}
+	}
+
+	opts.LoadedCAPool = caCertPool
+	return opts, nil
+}
+
+// proxy returns the proxy function of the http transport. Without an explicit proxy url,
+// the proxy is read from the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+func (o TransportOptions) proxy() (func(*http.Request) (*neturl.URL, error), error) {
+	if o.ProxyURL == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+
+	proxyURL, err := neturl.Parse(o.ProxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing the proxy URL %s: %s", o.ProxyURL, err)
+	}
+	if o.ProxyUsername != "" {
+		proxyURL.User = neturl.UserPassword(o.ProxyUsername, o.ProxyPassword)
+	}
+
+	proxyConfig := httpproxy.Config{
+		HTTPProxy:  proxyURL.String(),
+		HTTPSProxy: proxyURL.String(),
+		NoProxy:    o.NoProxy,
+	}
+	proxyFunc := proxyConfig.ProxyFunc()
+	return func(req *http.Request) (*neturl.URL, error) {
+		return proxyFunc(req.URL)
+	}, nil
+}
+
+// wrapTransport wraps the http transports of the provider. The tests replace it to record or replay the API
+// interactions.
+var wrapTransport = func(transport http.RoundTripper) (http.RoundTripper, error) {
+	return transport, nil
+}
+
+func createTransport(transportOptions TransportOptions) (http.RoundTripper, error) {
+	tlsClientOptions, err := transportOptions.tlsClientOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := httptransport.TLSClientAuth(tlsClientOptions)
 	if err != nil {
 		return nil, err
 	}
 
-	return &http.Transport{
+	proxy, err := transportOptions.proxy()
+	if err != nil {
+		return nil, err
+	}
+
+	return wrapTransport(&http.Transport{
 		TLSClientConfig: cfg,
-		Proxy:           http.ProxyFromEnvironment,
-	}, nil
+		Proxy:           proxy,
+	})
 }
 
-func getAPIClient(url string, token string, insecure bool, apiTimeout int) (*client.API, error) {
+func getAPIClient(url string, token string, transportOptions TransportOptions, apiTimeout int, retryOptions RetryOptions, logHTTPBody bool) (*client.API, error) {
 	parsedURL, err := neturl.Parse(url)
 	if err != nil {
 		return nil, err
@@ -342,15 +662,16 @@ func getAPIClient(url string, token string, insecure bool, apiTimeout int) (*cli
 
 	t := httptransport.New(parsedURL.Host, parsedURL.Path, nil)
 	t.DefaultAuthentication = httptransport.APIKeyAuth("Authorization", "header", "Bearer "+token)
-	newTransport, err := createTransport(insecure)
+	newTransport, err := createTransport(transportOptions)
 	if err != nil {
 		return nil, err
 	}
 
-	// Setup logging through the terraform helper
-	t.Transport = logging.NewSubsystemLoggingHTTPTransport("VRA", newTransport)
+	// Retry transient errors. The http traffic is only logged by the swagger logger, which redacts the credentials and
+	// honors log_http_body, so it is not logged through the terraform helper as well.
+	t.Transport = newRetryTransport(newTransport, retryOptions)
 	t.SetDebug(true)
-	t.SetLogger(SwaggerLogger{})
+	t.SetLogger(SwaggerLogger{OmitBody: !logHTTPBody})
 	apiclient := client.New(t, strfmt.Default)
 	return apiclient, nil
 }
@@ -0,0 +1,109 @@
+// © Broadcom. All Rights Reserved.
+// The term “Broadcom” refers to Broadcom Inc. and/or its subsidiaries.
+// SPDX-License-Identifier: MPL-2.0
+
+package vra
+
+import (
+	"context"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/vra-sdk-go/pkg/client/about"
+)
+
+func dataSourceAbout() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceAboutRead,
+
+		Schema: map[string]*schema.Schema{
+			"build": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The build number of the product, if known.",
+			},
+			"catalog_api_version": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The version of the Catalog API used by the provider.",
+			},
+			"deployments_api_version": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The version of the Deployment API used by the provider.",
+			},
+			"iaas_api_version": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The version of the IaaS API used by the provider.",
+			},
+			"iaas_latest_api_version": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The latest version of the IaaS API supported by the appliance.",
+			},
+			"iaas_supported_api_versions": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The versions of the IaaS API supported by the appliance.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"organization": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The organization the provider is configured with.",
+			},
+			"product": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The product of the appliance, either vRA or VCFA.",
+			},
+			"version": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The full version of the product, if known.",
+			},
+		},
+	}
+}
+
+func dataSourceAboutRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+
+	info, err := c.productInfo()
+	if err != nil {
+		return diag.Errorf("error detecting the product of the appliance: %s", err)
+	}
+
+	d.SetId(c.url)
+	d.Set("build", info.build)
+	d.Set("catalog_api_version", CatalogAPIVersion)
+	d.Set("deployments_api_version", DeploymentsAPIVersion)
+	d.Set("iaas_api_version", IaaSAPIVersion)
+	d.Set("organization", c.organization)
+	d.Set("product", info.product)
+	d.Set("version", info.version)
+
+	// The IaaS about page is informative only, it may not be available with the provider organization
+	getResp, err := c.apiClient.About.GetAboutPage(about.NewGetAboutPageParams())
+	if err != nil {
+		log.Printf("[WARN] Unable to retrieve the IaaS API versions: %s", err)
+		return nil
+	}
+
+	supportedAPIVersions := make([]string, 0, len(getResp.Payload.SupportedApis))
+	for _, api := range getResp.Payload.SupportedApis {
+		if api.APIVersion != nil {
+			supportedAPIVersions = append(supportedAPIVersions, *api.APIVersion)
+		}
+	}
+	if getResp.Payload.LatestAPIVersion != nil {
+		d.Set("iaas_latest_api_version", getResp.Payload.LatestAPIVersion)
+	}
+	d.Set("iaas_supported_api_versions", supportedAPIVersions)
+
+	return nil
+}
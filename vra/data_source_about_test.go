@@ -0,0 +1,56 @@
+// © Broadcom. All Rights Reserved.
+// The term “Broadcom” refers to Broadcom Inc. and/or its subsidiaries.
+// SPDX-License-Identifier: MPL-2.0
+
+package vra
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDataSourceAboutFakeVRA(t *testing.T) {
+	var tests = []struct {
+		applicationVersion string
+		product            string
+		version            string
+		build              string
+	}{
+		{"", ProductVRA, "", ""},
+		{"VMware Aria Automation 8.18.1.35409", ProductVRA, "8.18.1.35409", ""},
+		{"VMware Cloud Foundation Automation v9.0.0.0 (build 24701403)", ProductVCFA, "9.0.0.0", "24701403"},
+		{"VCF Automation 9.0.1.0-24965341", ProductVCFA, "9.0.1.0", "24965341"},
+	}
+
+	for _, tt := range tests {
+		fake := newFakeVRA(t)
+		fake.applicationVersion = tt.applicationVersion
+		m := fake.client(t)
+		m.organization = "acme"
+
+		r := dataSourceAbout()
+		d := r.TestResourceData()
+		if diags := r.ReadContext(context.Background(), d, m); diags.HasError() {
+			t.Fatalf("dataSourceAboutRead returned error %v", diags)
+		}
+
+		expected := map[string]string{
+			"product":                 tt.product,
+			"version":                 tt.version,
+			"build":                   tt.build,
+			"organization":            "acme",
+			"iaas_api_version":        IaaSAPIVersion,
+			"catalog_api_version":     CatalogAPIVersion,
+			"deployments_api_version": DeploymentsAPIVersion,
+			"iaas_latest_api_version": IaaSAPIVersion,
+		}
+		for key, value := range expected {
+			if actual := d.Get(key).(string); actual != value {
+				t.Errorf("dataSourceAboutRead for %q expected %s %q, actual %q", tt.applicationVersion, key, value, actual)
+			}
+		}
+		if versions := d.Get("iaas_supported_api_versions").([]interface{}); len(versions) != 2 {
+			t.Errorf("dataSourceAboutRead for %q expected 2 supported IaaS API versions, actual %v", tt.applicationVersion, versions)
+		}
+	}
+}
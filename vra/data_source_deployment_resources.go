@@ -0,0 +1,406 @@
+// © Broadcom. All Rights Reserved.
+// The term “Broadcom” refers to Broadcom Inc. and/or its subsidiaries.
+// SPDX-License-Identifier: MPL-2.0
+
+package vra
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/vra-sdk-go/pkg/client/deployments"
+	"github.com/vmware/vra-sdk-go/pkg/models"
+)
+
+func dataSourceDeploymentResources() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceDeploymentResourcesRead,
+
+		Schema: map[string]*schema.Schema{
+			"deployment_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The id of the deployment.",
+			},
+			"disks": deploymentResourcesSchema("The disks and volumes of the deployment.", map[string]*schema.Schema{
+				"capacity_gb": {
+					Type:        schema.TypeInt,
+					Computed:    true,
+					Description: "The capacity of the disk in GB.",
+				},
+				"persistent": {
+					Type:        schema.TypeBool,
+					Computed:    true,
+					Description: "Indicates whether the disk is kept when the machine it is attached to is deleted.",
+				},
+			}),
+			"load_balancers": deploymentResourcesSchema("The load balancers of the deployment.", map[string]*schema.Schema{
+				"address": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "The address of the load balancer.",
+				},
+				"internet_facing": {
+					Type:        schema.TypeBool,
+					Computed:    true,
+					Description: "Indicates whether the load balancer is exposed to the internet.",
+				},
+			}),
+			"machines": deploymentResourcesSchema("The machines of the deployment.", map[string]*schema.Schema{
+				"address": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "The primary address of the machine.",
+				},
+				"addresses": {
+					Type:        schema.TypeList,
+					Computed:    true,
+					Description: "All the addresses of the machine, the primary address first.",
+					Elem: &schema.Schema{
+						Type: schema.TypeString,
+					},
+				},
+				"cpu_count": {
+					Type:        schema.TypeInt,
+					Computed:    true,
+					Description: "The number of CPUs of the machine.",
+				},
+				"flavor": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "The flavor of the machine.",
+				},
+				"image": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "The image of the machine.",
+				},
+				"network_interfaces": {
+					Type:        schema.TypeList,
+					Computed:    true,
+					Description: "The network interfaces of the machine.",
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"address": {
+								Type:        schema.TypeString,
+								Computed:    true,
+								Description: "The address of the network interface.",
+							},
+							"device_index": {
+								Type:        schema.TypeInt,
+								Computed:    true,
+								Description: "The index of the network interface on the machine.",
+							},
+							"mac_address": {
+								Type:        schema.TypeString,
+								Computed:    true,
+								Description: "The MAC address of the network interface.",
+							},
+							"name": {
+								Type:        schema.TypeString,
+								Computed:    true,
+								Description: "The name of the network interface.",
+							},
+							"network": {
+								Type:        schema.TypeString,
+								Computed:    true,
+								Description: "The network the network interface is attached to.",
+							},
+						},
+					},
+				},
+				"power_state": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "The power state of the machine.",
+				},
+				"total_memory_mb": {
+					Type:        schema.TypeInt,
+					Computed:    true,
+					Description: "The memory of the machine in MB.",
+				},
+			}),
+			"names": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "The names of the resources in the cloud template to return. All the resources are returned when not provided.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"networks": deploymentResourcesSchema("The networks of the deployment.", map[string]*schema.Schema{
+				"cidr": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "The CIDR of the network.",
+				},
+				"domain": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "The domain of the network.",
+				},
+				"gateway": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "The gateway of the network.",
+				},
+				"network_type": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "The type of the network, such as existing, public, private, outbound or routed.",
+				},
+			}),
+			"resource_types": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "The types of the resources to return, such as Cloud.vSphere.Machine. All the resources are returned when not provided.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"security_groups": deploymentResourcesSchema("The security groups of the deployment.", map[string]*schema.Schema{
+				"security_group_type": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "The type of the security group, such as existing or new.",
+				},
+			}),
+		},
+	}
+}
+
+// deploymentResourcesSchema returns the schema of a list of deployment resources of a kind, with the properties
+// common to all the resources and the properties of the kind.
+func deploymentResourcesSchema(description string, properties map[string]*schema.Schema) *schema.Schema {
+	resourceSchema := map[string]*schema.Schema{
+		"id": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "The id of the resource.",
+		},
+		"name": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "The name of the resource in the cloud template.",
+		},
+		"properties_json": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "All the properties of the resource as a JSON object.",
+		},
+		"state": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "The state of the resource.",
+		},
+		"sync_status": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "The sync status of the resource.",
+		},
+		"type": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "The type of the resource.",
+		},
+	}
+	for key, value := range properties {
+		resourceSchema[key] = value
+	}
+
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Computed:    true,
+		Description: description,
+		Elem: &schema.Resource{
+			Schema: resourceSchema,
+		},
+	}
+}
+
+func dataSourceDeploymentResourcesRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	apiClient := m.(*Client).apiClient
+
+	deploymentID := d.Get("deployment_id").(string)
+	params := deployments.NewGetDeploymentResourcesUsingGET2Params().
+		WithDeploymentID(strfmt.UUID(deploymentID)).
+		WithAPIVersion(withString(DeploymentsAPIVersion)).
+		WithDollarTop(withInt32(DefaultDollarTop))
+	if v, ok := d.GetOk("names"); ok {
+		params = params.WithNames(expandStringList(v.([]interface{})))
+	}
+	if v, ok := d.GetOk("resource_types"); ok {
+		params = params.WithResourceTypes(expandStringList(v.([]interface{})))
+	}
+
+	getResp, err := apiClient.Deployments.GetDeploymentResourcesUsingGET2(params)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	resourcesByKind := map[string][]map[string]interface{}{
+		"disks":           make([]map[string]interface{}, 0),
+		"load_balancers":  make([]map[string]interface{}, 0),
+		"machines":        make([]map[string]interface{}, 0),
+		"networks":        make([]map[string]interface{}, 0),
+		"security_groups": make([]map[string]interface{}, 0),
+	}
+	for _, resource := range getResp.GetPayload().Content {
+		kind := deploymentResourceKind(*resource.Type)
+		if kind == "" {
+			continue
+		}
+		resourcesByKind[kind] = append(resourcesByKind[kind], flattenDeploymentResourceOfKind(resource, kind))
+	}
+
+	d.SetId(deploymentID)
+	for kind, resources := range resourcesByKind {
+		if err := d.Set(kind, resources); err != nil {
+			return diag.Errorf("error setting deployment %s - error: %#v", kind, err)
+		}
+	}
+
+	return nil
+}
+
+// deploymentResourceKind returns the list of the data source the resource of the type belongs to, or "" if the type
+// is not one of the typed resources.
+func deploymentResourceKind(resourceType string) string {
+	switch {
+	case resourceType == "Cloud.AWS.EC2.Instance" || strings.HasSuffix(resourceType, ".Machine"):
+		return "machines"
+	case strings.HasSuffix(resourceType, ".Network"):
+		return "networks"
+	case strings.HasSuffix(resourceType, ".Volume") || strings.HasSuffix(resourceType, ".Disk"):
+		return "disks"
+	case strings.HasSuffix(resourceType, ".LoadBalancer"):
+		return "load_balancers"
+	case strings.HasSuffix(resourceType, ".SecurityGroup"):
+		return "security_groups"
+	default:
+		return ""
+	}
+}
+
+func flattenDeploymentResourceOfKind(resource *models.DeploymentResource, kind string) map[string]interface{} {
+	properties, _ := resource.Properties.(map[string]interface{})
+	propertiesJSON, _ := json.Marshal(resource.Properties)
+
+	helper := make(map[string]interface{})
+	helper["id"] = resource.ID.String()
+	helper["name"] = *resource.Name
+	helper["properties_json"] = string(propertiesJSON)
+	helper["state"] = resource.State
+	helper["sync_status"] = resource.SyncStatus
+	helper["type"] = *resource.Type
+
+	switch kind {
+	case "disks":
+		helper["capacity_gb"] = resourcePropertyInt(properties, "capacityGb")
+		helper["persistent"] = resourcePropertyBool(properties, "persistent")
+	case "load_balancers":
+		helper["address"] = resourcePropertyString(properties, "address")
+		helper["internet_facing"] = resourcePropertyBool(properties, "internetFacing")
+	case "machines":
+		networkInterfaces, addresses := flattenMachineNetworkInterfaces(properties)
+		helper["address"] = resourcePropertyString(properties, "address")
+		helper["addresses"] = addresses
+		helper["cpu_count"] = resourcePropertyInt(properties, "cpuCount")
+		helper["flavor"] = resourcePropertyString(properties, "flavor")
+		helper["image"] = resourcePropertyString(properties, "image")
+		helper["network_interfaces"] = networkInterfaces
+		helper["power_state"] = resourcePropertyString(properties, "powerState")
+		helper["total_memory_mb"] = resourcePropertyInt(properties, "totalMemoryMB")
+	case "networks":
+		helper["cidr"] = resourcePropertyString(properties, "cidr")
+		helper["domain"] = resourcePropertyString(properties, "domain")
+		helper["gateway"] = resourcePropertyString(properties, "gateway")
+		helper["network_type"] = resourcePropertyString(properties, "networkType")
+	case "security_groups":
+		helper["security_group_type"] = resourcePropertyString(properties, "securityGroupType")
+	}
+
+	return helper
+}
+
+// flattenMachineNetworkInterfaces returns the network interfaces of the machine with the properties, and all the
+// addresses of the machine, the primary address first.
+func flattenMachineNetworkInterfaces(properties map[string]interface{}) ([]map[string]interface{}, []string) {
+	networkInterfaces := make([]map[string]interface{}, 0)
+	addresses := make([]string, 0)
+	addAddress := func(address string) {
+		if address == "" {
+			return
+		}
+		for _, a := range addresses {
+			if a == address {
+				return
+			}
+		}
+		addresses = append(addresses, address)
+	}
+
+	addAddress(resourcePropertyString(properties, "address"))
+
+	networks, _ := properties["networks"].([]interface{})
+	for _, value := range networks {
+		network, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		macAddress := resourcePropertyString(network, "mac_address")
+		if macAddress == "" {
+			macAddress = resourcePropertyString(network, "macAddress")
+		}
+
+		helper := make(map[string]interface{})
+		helper["address"] = resourcePropertyString(network, "address")
+		helper["device_index"] = resourcePropertyInt(network, "deviceIndex")
+		helper["mac_address"] = macAddress
+		helper["name"] = resourcePropertyString(network, "name")
+		helper["network"] = resourcePropertyString(network, "network")
+		networkInterfaces = append(networkInterfaces, helper)
+
+		addAddress(resourcePropertyString(network, "address"))
+		if ipv6Addresses, ok := network["ipv6Addresses"].([]interface{}); ok {
+			for _, address := range ipv6Addresses {
+				if s, ok := address.(string); ok {
+					addAddress(s)
+				}
+			}
+		}
+	}
+
+	return networkInterfaces, addresses
+}
+
+func resourcePropertyString(properties map[string]interface{}, key string) string {
+	if v, ok := properties[key].(string); ok {
+		return v
+	}
+	return ""
+}
+
+func resourcePropertyInt(properties map[string]interface{}, key string) int {
+	switch v := properties[key].(type) {
+	case float64:
+		return int(v)
+	case json.Number:
+		i, _ := v.Int64()
+		return int(i)
+	default:
+		return 0
+	}
+}
+
+func resourcePropertyBool(properties map[string]interface{}, key string) bool {
+	if v, ok := properties[key].(bool); ok {
+		return v
+	}
+	return false
+}
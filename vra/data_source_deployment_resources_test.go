@@ -0,0 +1,189 @@
+// © Broadcom. All Rights Reserved.
+// The term “Broadcom” refers to Broadcom Inc. and/or its subsidiaries.
+// SPDX-License-Identifier: MPL-2.0
+
+package vra
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/vmware/vra-sdk-go/pkg/models"
+)
+
+func TestAccDataSourceVRADeploymentResources(t *testing.T) {
+	rInt := acctest.RandInt()
+	resourceName1 := "vra_deployment.this"
+	dataSourceName1 := "data.vra_deployment_resources.this"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheckDeploymentDataSource(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceVRADeploymentResourcesConfig(rInt),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair(resourceName1, "id", dataSourceName1, "deployment_id"),
+					resource.TestCheckResourceAttr(dataSourceName1, "disks.#", "0"),
+					resource.TestCheckResourceAttr(dataSourceName1, "load_balancers.#", "0"),
+					resource.TestCheckResourceAttr(dataSourceName1, "machines.#", "0"),
+					resource.TestCheckResourceAttr(dataSourceName1, "networks.#", "0"),
+					resource.TestCheckResourceAttr(dataSourceName1, "security_groups.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDataSourceVRADeploymentResourcesConfig(rInt int) string {
+	// The cloud template of the deployment has no resources
+	return testAccDataSourceVRADeployment(rInt) + `
+		data "vra_deployment_resources" "this" {
+			deployment_id = vra_deployment.this.id
+		}`
+}
+
+func TestDataSourceDeploymentResourcesFakeVRA(t *testing.T) {
+	fake, m, deploymentResource := newFakeVRADeployment(t)
+
+	blueprintID := fake.addBlueprint("blueprint", map[string]interface{}{})
+	fake.setBlueprintResources(blueprintID, "", map[string]string{
+		"Cloud_vSphere_Machine_1":  "Cloud.vSphere.Machine",
+		"Cloud_vSphere_Machine_2":  "Cloud.vSphere.Machine",
+		"Cloud_vSphere_Network_1":  "Cloud.vSphere.Network",
+		"Cloud_vSphere_Disk_1":     "Cloud.vSphere.Disk",
+		"Cloud_LoadBalancer_1":     "Cloud.LoadBalancer",
+		"Cloud_SecurityGroup_1":    "Cloud.SecurityGroup",
+		"Custom_Ansible_1":         "Cloud.Ansible",
+		"Cloud_AWS_EC2_Instance_1": "Cloud.AWS.EC2.Instance",
+	})
+	state := testResourceApply(t, deploymentResource, nil, map[string]interface{}{
+		"name":         "deployment",
+		"project_id":   "project-id",
+		"blueprint_id": blueprintID,
+	}, m)
+
+	fake.updateDeployment(state.ID, func(deployment *models.Deployment) {
+		for _, resource := range deployment.Resources {
+			switch *resource.Name {
+			case "Cloud_vSphere_Machine_1":
+				resource.Properties = map[string]interface{}{
+					"address":       "10.0.0.11",
+					"powerState":    "ON",
+					"flavor":        "small",
+					"image":         "ubuntu",
+					"cpuCount":      float64(2),
+					"totalMemoryMB": float64(4096),
+					"networks": []interface{}{
+						map[string]interface{}{
+							"name":        "Cloud_vSphere_Network_1",
+							"network":     "network-id",
+							"address":     "10.0.0.11",
+							"mac_address": "00:50:56:00:00:01",
+							"deviceIndex": float64(0),
+						},
+						map[string]interface{}{
+							"name":          "Cloud_vSphere_Network_2",
+							"address":       "192.168.0.11",
+							"ipv6Addresses": []interface{}{"fd00::11"},
+							"deviceIndex":   float64(1),
+						},
+					},
+				}
+			case "Cloud_vSphere_Network_1":
+				resource.Properties = map[string]interface{}{
+					"cidr":        "10.0.0.0/24",
+					"gateway":     "10.0.0.1",
+					"networkType": "existing",
+				}
+			case "Cloud_vSphere_Disk_1":
+				resource.Properties = map[string]interface{}{"capacityGb": float64(20), "persistent": true}
+			case "Cloud_LoadBalancer_1":
+				resource.Properties = map[string]interface{}{"address": "10.0.0.100", "internetFacing": true}
+			case "Cloud_SecurityGroup_1":
+				resource.Properties = map[string]interface{}{"securityGroupType": "new"}
+			}
+		}
+	})
+
+	var tests = []struct {
+		config   map[string]interface{}
+		expected map[string]string
+	}{
+		{
+			map[string]interface{}{},
+			map[string]string{
+				"machines.#":                                   "3",
+				"machines.0.name":                              "Cloud_AWS_EC2_Instance_1",
+				"machines.1.name":                              "Cloud_vSphere_Machine_1",
+				"machines.1.type":                              "Cloud.vSphere.Machine",
+				"machines.1.address":                           "10.0.0.11",
+				"machines.1.addresses.#":                       "3",
+				"machines.1.addresses.0":                       "10.0.0.11",
+				"machines.1.addresses.1":                       "192.168.0.11",
+				"machines.1.addresses.2":                       "fd00::11",
+				"machines.1.power_state":                       "ON",
+				"machines.1.flavor":                            "small",
+				"machines.1.image":                             "ubuntu",
+				"machines.1.cpu_count":                         "2",
+				"machines.1.total_memory_mb":                   "4096",
+				"machines.1.network_interfaces.#":              "2",
+				"machines.1.network_interfaces.0.network":      "network-id",
+				"machines.1.network_interfaces.0.mac_address":  "00:50:56:00:00:01",
+				"machines.1.network_interfaces.1.device_index": "1",
+				"networks.#":                                   "1",
+				"networks.0.cidr":                              "10.0.0.0/24",
+				"networks.0.gateway":                           "10.0.0.1",
+				"networks.0.network_type":                      "existing",
+				"disks.#":                                      "1",
+				"disks.0.capacity_gb":                          "20",
+				"disks.0.persistent":                           "true",
+				"load_balancers.#":                             "1",
+				"load_balancers.0.address":                     "10.0.0.100",
+				"load_balancers.0.internet_facing":             "true",
+				"security_groups.#":                            "1",
+				"security_groups.0.security_group_type":        "new",
+			},
+		},
+		{
+			map[string]interface{}{"resource_types": []interface{}{"Cloud.vSphere.Machine"}},
+			map[string]string{
+				"machines.#":      "2",
+				"machines.0.name": "Cloud_vSphere_Machine_1",
+				"machines.1.name": "Cloud_vSphere_Machine_2",
+				"networks.#":      "0",
+			},
+		},
+		{
+			map[string]interface{}{"names": []interface{}{"Cloud_vSphere_Machine_2", "Cloud_vSphere_Disk_1"}},
+			map[string]string{
+				"machines.#":      "1",
+				"machines.0.name": "Cloud_vSphere_Machine_2",
+				"disks.#":         "1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		r := dataSourceDeploymentResources()
+		d := r.TestResourceData()
+		d.Set("deployment_id", state.ID)
+		for key, value := range tt.config {
+			d.Set(key, value)
+		}
+		if diags := r.ReadContext(context.Background(), d, m); diags.HasError() {
+			t.Fatalf("dataSourceDeploymentResourcesRead returned error %v", diags)
+		}
+
+		if d.Id() != state.ID {
+			t.Errorf("dataSourceDeploymentResourcesRead expected id %q, actual %q", state.ID, d.Id())
+		}
+		for key, value := range tt.expected {
+			if actual := d.State().Attributes[key]; actual != value {
+				t.Errorf("dataSourceDeploymentResourcesRead with %v expected %s %q, actual %q", tt.config, key, value, actual)
+			}
+		}
+	}
+}
@@ -0,0 +1,283 @@
+// © Broadcom. All Rights Reserved.
+// The term “Broadcom” refers to Broadcom Inc. and/or its subsidiaries.
+// SPDX-License-Identifier: MPL-2.0
+
+package vra
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/vmware/vra-sdk-go/pkg/client/deployments"
+	"github.com/vmware/vra-sdk-go/pkg/models"
+)
+
+// Bounds of the lease expiration window when only one of them is provided
+const (
+	deploymentsLeaseExpireAfterMin  = "1970-01-01T00:00:00Z"
+	deploymentsLeaseExpireBeforeMax = "9999-12-31T23:59:59Z"
+)
+
+func dataSourceDeployments() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceDeploymentsRead,
+
+		Schema: map[string]*schema.Schema{
+			"catalog_item_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The id of the catalog item the deployments were requested from. This filter is applied client-side once the deployments matching the other filters are retrieved.",
+			},
+			"deployments": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The deployments matching the filters.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"blueprint_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The id of the cloud template used to request the deployment.",
+						},
+						"blueprint_version": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The version of the cloud template used to request the deployment.",
+						},
+						"catalog_item_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The id of the catalog item used to request the deployment.",
+						},
+						"catalog_item_version": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The version of the catalog item used to request the deployment.",
+						},
+						"created_at": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Date when the entity was created. The date is in ISO 6801 and UTC.",
+						},
+						"created_by": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The user the entity was created by.",
+						},
+						"description": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "A human-friendly description.",
+						},
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The id of the deployment.",
+						},
+						"last_updated_at": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Date when the entity was last updated. The date is in ISO 6801 and UTC.",
+						},
+						"last_updated_by": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The user that last updated the deployment.",
+						},
+						"lease_expire_at": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Date when the deployment lease expire. The date is in ISO 6801 and UTC.",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the deployment.",
+						},
+						"org_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The Id of the organization this deployment belongs to.",
+						},
+						"owner": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The user this deployment belongs to.",
+						},
+						"project_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The id of the project this deployment belongs to.",
+						},
+						"status": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The status of the deployment with respect to its life cycle operations.",
+						},
+					},
+				},
+			},
+			"ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The ids of the deployments matching the filters.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"lease_expire_after": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateLeaseExpireAt,
+				Description:  "Only the deployments whose lease expires after this date are returned, as a RFC 3339 timestamp.",
+			},
+			"lease_expire_before": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateLeaseExpireAt,
+				Description:  "Only the deployments whose lease expires before this date are returned, as a RFC 3339 timestamp.",
+			},
+			"owners": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "The users the deployments belong to.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"project_ids": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "The ids of the projects the deployments belong to.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"statuses": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "The statuses of the deployments.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+					ValidateFunc: validation.StringInSlice([]string{
+						models.DeploymentStatusCREATESUCCESSFUL, models.DeploymentStatusCREATEINPROGRESS, models.DeploymentStatusCREATEFAILED,
+						models.DeploymentStatusUPDATESUCCESSFUL, models.DeploymentStatusUPDATEINPROGRESS, models.DeploymentStatusUPDATEFAILED,
+						models.DeploymentStatusDELETESUCCESSFUL, models.DeploymentStatusDELETEINPROGRESS, models.DeploymentStatusDELETEFAILED,
+					}, false),
+				},
+			},
+			"tags": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "The tags of the deployments, in the key:value format.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+func dataSourceDeploymentsRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	apiClient := m.(*Client).apiClient
+
+	params := deployments.NewGetDeploymentsV3UsingGETParams().
+		WithAPIVersion(withString(DeploymentsAPIVersion)).
+		WithDollarOrderby([]string{"name"}).
+		WithDollarTop(withInt32(DefaultDollarTop))
+	if v, ok := d.GetOk("owners"); ok {
+		params = params.WithOwnedBy(expandStringList(v.([]interface{})))
+	}
+	if v, ok := d.GetOk("project_ids"); ok {
+		params = params.WithProjects(expandStringList(v.([]interface{})))
+	}
+	if v, ok := d.GetOk("statuses"); ok {
+		params = params.WithStatus(expandStringList(v.([]interface{})))
+	}
+	if v, ok := d.GetOk("tags"); ok {
+		params = params.WithTags(expandStringList(v.([]interface{})))
+	}
+
+	leaseExpireAfter, afterOk := d.GetOk("lease_expire_after")
+	leaseExpireBefore, beforeOk := d.GetOk("lease_expire_before")
+	if afterOk || beforeOk {
+		if !afterOk {
+			leaseExpireAfter = deploymentsLeaseExpireAfterMin
+		}
+		if !beforeOk {
+			leaseExpireBefore = deploymentsLeaseExpireBeforeMax
+		}
+		after, _ := time.Parse(time.RFC3339, leaseExpireAfter.(string))
+		before, _ := time.Parse(time.RFC3339, leaseExpireBefore.(string))
+		if before.Before(after) {
+			return diag.Errorf("lease_expire_before %s must not be before lease_expire_after %s", leaseExpireBefore, leaseExpireAfter)
+		}
+		params = params.WithExpiresAt(withString(fmt.Sprintf("%s,%s", leaseExpireAfter, leaseExpireBefore)))
+	}
+
+	// The deployments are paged through, since there may be more deployments than the maximum number of deployments
+	// in a page
+	allDeployments := make([]*models.Deployment, 0)
+	for {
+		params = params.WithDollarSkip(withInt32(int32(len(allDeployments))))
+		getResp, err := apiClient.Deployments.GetDeploymentsV3UsingGET(params)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		page := getResp.GetPayload()
+		allDeployments = append(allDeployments, page.Content...)
+		if page.Last || len(page.Content) == 0 || int64(len(allDeployments)) >= page.TotalElements {
+			break
+		}
+	}
+	log.Printf("Found %d deployments", len(allDeployments))
+
+	catalogItemID := d.Get("catalog_item_id").(string)
+	ids := make([]string, 0, len(allDeployments))
+	deploymentsList := make([]map[string]interface{}, 0, len(allDeployments))
+	for _, deployment := range allDeployments {
+		// The catalog item is not a filter of the API, so it is filtered client-side
+		if catalogItemID != "" && deployment.CatalogItemID != catalogItemID {
+			continue
+		}
+
+		helper := make(map[string]interface{})
+		helper["blueprint_id"] = deployment.BlueprintID
+		helper["blueprint_version"] = deployment.BlueprintVersion
+		helper["catalog_item_id"] = deployment.CatalogItemID
+		helper["catalog_item_version"] = deployment.CatalogItemVersion
+		helper["created_at"] = deployment.CreatedAt.String()
+		helper["created_by"] = deployment.CreatedBy
+		helper["description"] = deployment.Description
+		helper["id"] = deployment.ID.String()
+		helper["last_updated_at"] = deployment.LastUpdatedAt.String()
+		helper["last_updated_by"] = deployment.LastUpdatedBy
+		helper["lease_expire_at"] = deployment.LeaseExpireAt.String()
+		helper["name"] = *deployment.Name
+		helper["org_id"] = deployment.OrgID
+		helper["owner"] = deployment.OwnedBy
+		helper["project_id"] = deployment.ProjectID
+		helper["status"] = deployment.Status
+
+		ids = append(ids, deployment.ID.String())
+		deploymentsList = append(deploymentsList, helper)
+	}
+
+	d.SetId(fmt.Sprintf("%x", sha256.Sum256([]byte(strings.Join(ids, ",")))))
+	if err := d.Set("ids", ids); err != nil {
+		return diag.Errorf("error setting deployments ids - error: %#v", err)
+	}
+	if err := d.Set("deployments", deploymentsList); err != nil {
+		return diag.Errorf("error setting deployments - error: %#v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+// © Broadcom. All Rights Reserved.
+// The term “Broadcom” refers to Broadcom Inc. and/or its subsidiaries.
+// SPDX-License-Identifier: MPL-2.0
+
+package vra
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/vmware/vra-sdk-go/pkg/models"
+)
+
+func TestAccDataSourceVRADeployments(t *testing.T) {
+	rInt := acctest.RandInt()
+	resourceName1 := "vra_deployment.this"
+	dataSourceName1 := "data.vra_deployments.this"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheckDeploymentDataSource(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceVRADeploymentsCatalogItemConfig(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.vra_deployments.this", "deployments.#", "0"),
+				),
+			},
+			{
+				Config: testAccDataSourceVRADeploymentsProjectConfig(rInt),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName1, "deployments.#", "1"),
+					resource.TestCheckResourceAttrPair(resourceName1, "id", dataSourceName1, "ids.0"),
+					resource.TestCheckResourceAttrPair(resourceName1, "id", dataSourceName1, "deployments.0.id"),
+					resource.TestCheckResourceAttrPair(resourceName1, "name", dataSourceName1, "deployments.0.name"),
+					resource.TestCheckResourceAttrPair(resourceName1, "project_id", dataSourceName1, "deployments.0.project_id"),
+					resource.TestCheckResourceAttrPair(resourceName1, "blueprint_id", dataSourceName1, "deployments.0.blueprint_id"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDataSourceVRADeploymentsCatalogItemConfig() string {
+	return `data "vra_deployments" "this" {
+			catalog_item_id = "invalid-id"
+		}`
+}
+
+func testAccDataSourceVRADeploymentsProjectConfig(rInt int) string {
+	return testAccDataSourceVRADeployment(rInt) + `
+		data "vra_deployments" "this" {
+			project_ids = [vra_project.this.id]
+
+			depends_on = [vra_deployment.this]
+		}`
+}
+
+func TestDataSourceDeploymentsFakeVRA(t *testing.T) {
+	fake := newFakeVRA(t)
+	fake.pageSize = 2
+	m := fake.client(t)
+
+	leaseExpireAt := func(date string) func(deployment *models.Deployment) {
+		return func(deployment *models.Deployment) {
+			leaseExpireAt, _ := time.Parse(time.RFC3339, date)
+			deployment.LeaseExpireAt = strfmt.DateTime(leaseExpireAt)
+		}
+	}
+	fake.addDeployment("app-1", "project-a", leaseExpireAt("2030-01-15T00:00:00Z"), "env:prod")
+	fake.addDeployment("app-2", "project-a", func(deployment *models.Deployment) {
+		deployment.CatalogItemID = "catalog-item-id"
+		deployment.OwnedBy = "owner@example.com"
+		leaseExpireAt("2030-02-15T00:00:00Z")(deployment)
+	}, "env:dev")
+	fake.addDeployment("app-3", "project-b", func(deployment *models.Deployment) {
+		deployment.CatalogItemID = "catalog-item-id"
+		deployment.Status = models.DeploymentStatusCREATEFAILED
+	}, "env:prod")
+	fake.addDeployment("app-4", "project-b", leaseExpireAt("2030-03-15T00:00:00Z"))
+	fake.addDeployment("app-5", "project-c", nil, "env:prod", "team:web")
+
+	var tests = []struct {
+		config   map[string]interface{}
+		expected []string
+	}{
+		{map[string]interface{}{}, []string{"app-1", "app-2", "app-3", "app-4", "app-5"}},
+		{map[string]interface{}{"project_ids": []interface{}{"project-a", "project-c"}}, []string{"app-1", "app-2", "app-5"}},
+		{map[string]interface{}{"owners": []interface{}{"owner@example.com"}}, []string{"app-2"}},
+		{map[string]interface{}{"statuses": []interface{}{models.DeploymentStatusCREATEFAILED}}, []string{"app-3"}},
+		{map[string]interface{}{"tags": []interface{}{"env:prod"}}, []string{"app-1", "app-3", "app-5"}},
+		{map[string]interface{}{"tags": []interface{}{"env:prod", "team:web"}}, []string{"app-5"}},
+		{map[string]interface{}{"catalog_item_id": "catalog-item-id"}, []string{"app-2", "app-3"}},
+		{map[string]interface{}{"catalog_item_id": "catalog-item-id", "project_ids": []interface{}{"project-a"}}, []string{"app-2"}},
+		{map[string]interface{}{"catalog_item_id": "catalog-item-id", "tags": []interface{}{"env:prod"}}, []string{"app-3"}},
+		{map[string]interface{}{"lease_expire_after": "2030-02-01T00:00:00Z"}, []string{"app-2", "app-4"}},
+		{map[string]interface{}{"lease_expire_before": "2030-02-01T00:00:00Z"}, []string{"app-1"}},
+		{map[string]interface{}{"lease_expire_after": "2030-02-01T00:00:00Z", "lease_expire_before": "2030-03-01T00:00:00Z"}, []string{"app-2"}},
+		{map[string]interface{}{"project_ids": []interface{}{"project-d"}}, []string{}},
+	}
+
+	for _, tt := range tests {
+		r := dataSourceDeployments()
+		d := r.TestResourceData()
+		for key, value := range tt.config {
+			d.Set(key, value)
+		}
+		if diags := r.ReadContext(context.Background(), d, m); diags.HasError() {
+			t.Fatalf("dataSourceDeploymentsRead with %v returned error %v", tt.config, diags)
+		}
+
+		names := make([]string, 0)
+		for _, deployment := range d.Get("deployments").([]interface{}) {
+			names = append(names, deployment.(map[string]interface{})["name"].(string))
+		}
+		if !reflect.DeepEqual(names, tt.expected) {
+			t.Errorf("dataSourceDeploymentsRead with %v expected deployments %v, actual %v", tt.config, tt.expected, names)
+		}
+		if ids := d.Get("ids").([]interface{}); len(ids) != len(tt.expected) {
+			t.Errorf("dataSourceDeploymentsRead with %v expected %d ids, actual %v", tt.config, len(tt.expected), ids)
+		}
+	}
+
+	r := dataSourceDeployments()
+	d := r.TestResourceData()
+	d.Set("lease_expire_after", "2030-03-01T00:00:00Z")
+	d.Set("lease_expire_before", "2030-02-01T00:00:00Z")
+	if diags := r.ReadContext(context.Background(), d, m); !diags.HasError() {
+		t.Errorf("dataSourceDeploymentsRead expected an error for a lease expiration window ending before it starts")
+	}
+}
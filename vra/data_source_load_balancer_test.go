@@ -73,6 +73,8 @@ data "vra_load_balancer" "test" {
 }
 
 func testAccPreCheckLoadBalancerDataSource(t *testing.T) {
+	testAccRecorder(t)
+
 	if os.Getenv("VRA_AWS_CLOUD_ACCOUNT_NAME") == "" {
 		t.Fatal("VRA_AWS_CLOUD_ACCOUNT_NAME must be set for acceptance tests")
 	}
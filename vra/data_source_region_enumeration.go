@@ -9,7 +9,6 @@ import (
 	"errors"
 	"fmt"
 	"strings"
-	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
@@ -83,12 +82,12 @@ func dataSourceRegionEnumerationRead(ctx context.Context, d *schema.ResourceData
 	}
 
 	stateChangeFunc := retry.StateChangeConf{
-		Delay:      5 * time.Second,
+		Delay:      pollDelay,
 		Pending:    []string{models.RequestTrackerStatusINPROGRESS},
 		Refresh:    dataSourceRegionEnumerationReadRefreshFunc(*apiClient, *enumResp.Payload.ID),
 		Target:     []string{models.RequestTrackerStatusFINISHED},
 		Timeout:    d.Timeout(schema.TimeoutRead),
-		MinTimeout: 5 * time.Second,
+		MinTimeout: pollMinTimeout,
 	}
 
 	resourceIDs, err := stateChangeFunc.WaitForStateContext(ctx)
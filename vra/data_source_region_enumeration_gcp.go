@@ -6,7 +6,6 @@ package vra
 
 import (
 	"context"
-	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
@@ -70,12 +69,12 @@ func dataSourceRegionEnumerationGCPRead(ctx context.Context, d *schema.ResourceD
 	}
 
 	stateChangeFunc := retry.StateChangeConf{
-		Delay:      5 * time.Second,
+		Delay:      pollDelay,
 		Pending:    []string{models.RequestTrackerStatusINPROGRESS},
 		Refresh:    dataSourceRegionEnumerationReadRefreshFunc(*apiClient, *enumResp.Payload.ID),
 		Target:     []string{models.RequestTrackerStatusFINISHED},
 		Timeout:    d.Timeout(schema.TimeoutRead),
-		MinTimeout: 5 * time.Second,
+		MinTimeout: pollMinTimeout,
 	}
 
 	resourceIDs, err := stateChangeFunc.WaitForStateContext(ctx)
@@ -0,0 +1,190 @@
+// © Broadcom. All Rights Reserved.
+// The term “Broadcom” refers to Broadcom Inc. and/or its subsidiaries.
+// SPDX-License-Identifier: MPL-2.0
+
+package vra
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/vra-sdk-go/pkg/client"
+	"github.com/vmware/vra-sdk-go/pkg/models"
+)
+
+const (
+	// The request pending approval is waited for until it is approved or rejected, which is also the case when no
+	// value is set
+	DeploymentOnApprovalPendingWait = "wait"
+	// The request pending approval fails right away
+	DeploymentOnApprovalPendingFail = "fail"
+)
+
+// deploymentApproval is the approval of a deployment request, as returned by the Approval API which is not part of
+// the SDK.
+type deploymentApproval struct {
+	ID        string   `json:"id"`
+	RequestID string   `json:"requestId"`
+	Status    string   `json:"status"`
+	Approvers []string `json:"approvers"`
+	ActionBy  string   `json:"actionBy"`
+	Comment   string   `json:"comment"`
+}
+
+type deploymentApprovalPage struct {
+	Content []*deploymentApproval `json:"content"`
+}
+
+// deploymentApprovalSchema returns the schema to use for the approval property
+func deploymentApprovalSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Computed:    true,
+		Description: "The approval of the last request on the deployment, when the request is gated by an approval policy.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"action_by": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "The approver who approved or rejected the request.",
+				},
+				"approvers": {
+					Type:        schema.TypeList,
+					Computed:    true,
+					Description: "The users and groups who can approve the request.",
+					Elem: &schema.Schema{
+						Type: schema.TypeString,
+					},
+				},
+				"comment": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "The comment of the approver who approved or rejected the request.",
+				},
+				"id": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "The id of the approval request.",
+				},
+				"status": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "The status of the approval request.",
+				},
+			},
+		},
+	}
+}
+
+func flattenDeploymentApproval(approval *deploymentApproval) []map[string]interface{} {
+	if approval == nil {
+		return make([]map[string]interface{}, 0)
+	}
+
+	helper := make(map[string]interface{})
+	helper["action_by"] = approval.ActionBy
+	helper["approvers"] = approval.Approvers
+	helper["comment"] = approval.Comment
+	helper["id"] = approval.ID
+	helper["status"] = approval.Status
+
+	return []map[string]interface{}{helper}
+}
+
+// isDeploymentRequestApprovalRequired returns whether the deployment request went through an approval.
+func isDeploymentRequestApprovalRequired(request *models.Request) bool {
+	if request == nil {
+		return false
+	}
+	switch request.Status {
+	case models.RequestStatusAPPROVALPENDING, models.RequestStatusAPPROVALREJECTED:
+		return true
+	default:
+		return !time.Time(request.ApprovedAt).IsZero()
+	}
+}
+
+// getDeploymentRequestApproval returns the approval of the deployment request, or nil if the request has no approval.
+// The Approval API is not part of the SDK, so the approval only details the status of the request, which is read from
+// the Deployment API, and an approval which cannot be retrieved does not change the outcome of the request.
+func getDeploymentRequestApproval(apiClient *client.API, requestID strfmt.UUID) (*deploymentApproval, error) {
+	result, err := apiClient.Transport.Submit(&runtime.ClientOperation{
+		ID:                 "getApprovals",
+		Method:             http.MethodGet,
+		PathPattern:        "/approval/api/approvals",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{"https"},
+		Params: runtime.ClientRequestWriterFunc(func(r runtime.ClientRequest, _ strfmt.Registry) error {
+			return r.SetQueryParam("$filter", fmt.Sprintf("requestId eq '%s'", requestID))
+		}),
+		Reader: runtime.ClientResponseReaderFunc(func(response runtime.ClientResponse, consumer runtime.Consumer) (interface{}, error) {
+			if response.Code() != http.StatusOK {
+				return nil, runtime.NewAPIError("getApprovals", response.Message(), response.Code())
+			}
+			var page deploymentApprovalPage
+			if err := consumer.Consume(response.Body(), &page); err != nil {
+				return nil, err
+			}
+			return &page, nil
+		}),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, approval := range result.(*deploymentApprovalPage).Content {
+		if approval.RequestID == requestID.String() {
+			return approval, nil
+		}
+	}
+	return nil, nil
+}
+
+// getDeploymentRequestApprovalOrNil returns the approval of the deployment request, or nil if it cannot be retrieved,
+// so that the approval only adds details to the status of the request.
+func getDeploymentRequestApprovalOrNil(apiClient *client.API, requestID strfmt.UUID) *deploymentApproval {
+	approval, err := getDeploymentRequestApproval(apiClient, requestID)
+	if err != nil {
+		log.Printf("[WARN] Unable to retrieve the approval of request %s: %s", requestID, err)
+		return nil
+	}
+	return approval
+}
+
+// deploymentApprovalPendingError returns the error of a deployment request pending approval, with the approval request
+// and its approvers.
+func deploymentApprovalPendingError(apiClient *client.API, request *models.Request) error {
+	message := fmt.Sprintf("request %s is pending approval", request.ID)
+	if approval := getDeploymentRequestApprovalOrNil(apiClient, request.ID); approval != nil {
+		message += fmt.Sprintf(" %s", approval.ID)
+		if len(approval.Approvers) > 0 {
+			message += fmt.Sprintf(" by %s", strings.Join(approval.Approvers, ", "))
+		}
+	}
+	return fmt.Errorf("%s, not waiting for the approval since on_approval_pending is %s", message, DeploymentOnApprovalPendingFail)
+}
+
+// deploymentApprovalRejectedError returns the error of a rejected deployment request, with the approver who rejected
+// it and their comment.
+func deploymentApprovalRejectedError(apiClient *client.API, request *models.Request) error {
+	message := fmt.Sprintf("request %s was rejected", request.ID)
+	approval := getDeploymentRequestApprovalOrNil(apiClient, request.ID)
+	if approval != nil && approval.ActionBy != "" {
+		message += fmt.Sprintf(" by %s", approval.ActionBy)
+	}
+	switch {
+	case approval != nil && approval.Comment != "":
+		message += fmt.Sprintf(": %s", approval.Comment)
+	case request.Details != "":
+		message += fmt.Sprintf(": %s", request.Details)
+	}
+	return errors.New(message)
+}
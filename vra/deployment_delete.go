@@ -0,0 +1,172 @@
+// © Broadcom. All Rights Reserved.
+// The term “Broadcom” refers to Broadcom Inc. and/or its subsidiaries.
+// SPDX-License-Identifier: MPL-2.0
+
+package vra
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+	"github.com/vmware/vra-sdk-go/pkg/client"
+	"github.com/vmware/vra-sdk-go/pkg/client/deployment_actions"
+	"github.com/vmware/vra-sdk-go/pkg/client/deployments"
+	"github.com/vmware/vra-sdk-go/pkg/client/requests"
+	"github.com/vmware/vra-sdk-go/pkg/models"
+)
+
+const (
+	// The deployment is deleted when the resource is destroyed, which is also the case when no policy is set
+	DeploymentDeletionPolicyDelete = "delete"
+	// The deployment is only removed from the state when the resource is destroyed
+	DeploymentDeletionPolicyAbandon = "abandon"
+)
+
+// Name of the input of the Delete deployment action which ignores the failures to delete the resources
+const DeleteDeploymentActionIgnoreFailuresInputName = "ignoreDeleteFailures"
+
+// deploymentDeleteAttemptTimeout returns the timeout of a delete attempt, so that the attempts left share the time
+// remaining until the deadline of the delete.
+func deploymentDeleteAttemptTimeout(deadline time.Time, attemptsLeft int) time.Duration {
+	return time.Until(deadline) / time.Duration(attemptsLeft)
+}
+
+// deleteDeployment deletes the deployment and waits until it no longer exists.
+func deleteDeployment(ctx context.Context, apiClient *client.API, deploymentUUID strfmt.UUID, timeout time.Duration) error {
+	_, err := apiClient.Deployments.DeleteDeploymentUsingDELETE2(deployments.NewDeleteDeploymentUsingDELETE2Params().WithDeploymentID(deploymentUUID))
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Requested for deleting deployment %s", deploymentUUID)
+	return waitForDeploymentDeletion(ctx, apiClient, deploymentUUID, timeout)
+}
+
+// forceDeleteDeployment cancels the request in progress on the deployment, if any, so that a stuck deployment can be
+// deleted, and deletes the deployment ignoring the failures to delete its resources.
+func forceDeleteDeployment(ctx context.Context, apiClient *client.API, deploymentUUID strfmt.UUID, timeout time.Duration) error {
+	if err := cancelDeploymentRequest(ctx, apiClient, deploymentUUID, timeout); err != nil {
+		return err
+	}
+	return deleteDeploymentIgnoringFailures(ctx, apiClient, deploymentUUID, timeout)
+}
+
+// deleteDeploymentIgnoringFailures deletes the deployment with the Delete action, ignoring the failures to delete its
+// resources, and waits until it no longer exists.
+func deleteDeploymentIgnoringFailures(ctx context.Context, apiClient *client.API, deploymentUUID strfmt.UUID, timeout time.Duration) error {
+	isActionValid, actionID, err := getDeploymentDay2ActionID(apiClient, deploymentUUID, DeleteDeploymentActionName)
+	if err != nil {
+		return err
+	}
+	if !isActionValid {
+		return fmt.Errorf("'Delete' action is not found or supported on deployment %s", deploymentUUID)
+	}
+
+	resourceActionRequest := models.ResourceActionRequest{
+		ActionID: actionID,
+		Reason:   "Deleted deployment ignoring delete failures from vRA provider for Terraform.",
+		Inputs: map[string]interface{}{
+			DeleteDeploymentActionIgnoreFailuresInputName: true,
+		},
+	}
+
+	_, err = apiClient.DeploymentActions.SubmitDeploymentActionRequestUsingPOST2(
+		deployment_actions.NewSubmitDeploymentActionRequestUsingPOST2Params().
+			WithAPIVersion(withString(DeploymentsAPIVersion)).
+			WithDeploymentID(deploymentUUID).
+			WithActionRequest(&resourceActionRequest))
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Requested for deleting deployment %s ignoring delete failures", deploymentUUID)
+	return waitForDeploymentDeletion(ctx, apiClient, deploymentUUID, timeout)
+}
+
+func waitForDeploymentDeletion(ctx context.Context, apiClient *client.API, deploymentUUID strfmt.UUID, timeout time.Duration) error {
+	stateChangeFunc := retry.StateChangeConf{
+		Delay:      pollDelay,
+		Pending:    []string{reflect.TypeOf((*deployments.GetDeploymentByIDV3UsingGETOK)(nil)).String()},
+		Refresh:    deploymentDeleteStatusRefreshFunc(*apiClient, deploymentUUID.String()),
+		Target:     []string{reflect.TypeOf((*deployments.GetDeploymentByIDV3UsingGETNotFound)(nil)).String()},
+		Timeout:    timeout,
+		MinTimeout: pollMinTimeout,
+	}
+
+	_, err := stateChangeFunc.WaitForStateContext(ctx)
+	return err
+}
+
+// cancelDeploymentRequest cancels the last request of the deployment when it is still in progress, and waits until
+// the request is canceled.
+func cancelDeploymentRequest(ctx context.Context, apiClient *client.API, deploymentUUID strfmt.UUID, timeout time.Duration) error {
+	getResp, err := apiClient.Deployments.GetDeploymentByIDV3UsingGET(
+		deployments.NewGetDeploymentByIDV3UsingGETParams().
+			WithDeploymentID(deploymentUUID).
+			WithExpand([]string{"lastRequest"}).
+			WithAPIVersion(withString(DeploymentsAPIVersion)))
+	if err != nil {
+		return err
+	}
+
+	lastRequest := getResp.GetPayload().LastRequest
+	if lastRequest == nil || !isDeploymentRequestInProgress(lastRequest.Status) {
+		return nil
+	}
+	if !lastRequest.Cancelable {
+		log.Printf("[WARN] Request %s in progress on deployment %s cannot be canceled", lastRequest.ID, deploymentUUID)
+		return nil
+	}
+
+	log.Printf("Canceling request %s in progress on deployment %s", lastRequest.ID, deploymentUUID)
+	_, err = apiClient.Requests.ActionDeploymentRequestUsingPOST2(
+		requests.NewActionDeploymentRequestUsingPOST2Params().
+			WithRequestID(lastRequest.ID).
+			WithAction("cancel").
+			WithAPIVersion(withString(DeploymentsAPIVersion)))
+	if err != nil {
+		return fmt.Errorf("error canceling request %s in progress on deployment %s: %s", lastRequest.ID, deploymentUUID, err)
+	}
+
+	stateChangeFunc := retry.StateChangeConf{
+		Delay:      pollDelay,
+		Pending:    []string{"INPROGRESS"},
+		Refresh:    deploymentRequestCancelStatusRefreshFunc(*apiClient, lastRequest.ID),
+		Target:     []string{"DONE"},
+		Timeout:    timeout,
+		MinTimeout: pollMinTimeout,
+	}
+	if _, err := stateChangeFunc.WaitForStateContext(ctx); err != nil {
+		return fmt.Errorf("error canceling request %s in progress on deployment %s: %s", lastRequest.ID, deploymentUUID, err)
+	}
+	return nil
+}
+
+// isDeploymentRequestInProgress returns whether the deployment request with the status is not completed yet.
+func isDeploymentRequestInProgress(status string) bool {
+	switch status {
+	case models.RequestStatusCREATED, models.RequestStatusPENDING, models.RequestStatusINITIALIZATION, models.RequestStatusCHECKINGAPPROVAL, models.RequestStatusAPPROVALPENDING, models.RequestStatusINPROGRESS, models.RequestStatusCOMPLETION:
+		return true
+	default:
+		return false
+	}
+}
+
+func deploymentRequestCancelStatusRefreshFunc(apiClient client.API, requestID strfmt.UUID) retry.StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		ret, err := apiClient.Requests.GetRequestUsingGET2(requests.NewGetRequestUsingGET2Params().WithRequestID(requestID))
+		if err != nil {
+			return "", "", err
+		}
+
+		if isDeploymentRequestInProgress(ret.Payload.Status) {
+			return requestID.String(), "INPROGRESS", nil
+		}
+		return requestID.String(), "DONE", nil
+	}
+}
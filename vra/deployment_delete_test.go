@@ -0,0 +1,33 @@
+// © Broadcom. All Rights Reserved.
+// The term “Broadcom” refers to Broadcom Inc. and/or its subsidiaries.
+// SPDX-License-Identifier: MPL-2.0
+
+package vra
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeploymentDeleteAttemptTimeout(t *testing.T) {
+	deadline := time.Now().Add(30 * time.Minute)
+
+	var tests = []struct {
+		attemptsLeft int
+		min          time.Duration
+		max          time.Duration
+	}{
+		{1, 29 * time.Minute, 30 * time.Minute},
+		{3, 9 * time.Minute, 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if timeout := deploymentDeleteAttemptTimeout(deadline, tt.attemptsLeft); timeout < tt.min || timeout > tt.max {
+			t.Errorf("deploymentDeleteAttemptTimeout with %d attempts left expected between %s and %s, actual %s", tt.attemptsLeft, tt.min, tt.max, timeout)
+		}
+	}
+
+	if timeout := deploymentDeleteAttemptTimeout(time.Now().Add(-time.Minute), 1); timeout > 0 {
+		t.Errorf("deploymentDeleteAttemptTimeout expected no time left after the deadline, actual %s", timeout)
+	}
+}
@@ -0,0 +1,233 @@
+// © Broadcom. All Rights Reserved.
+// The term “Broadcom” refers to Broadcom Inc. and/or its subsidiaries.
+// SPDX-License-Identifier: MPL-2.0
+
+package vra
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/vra-sdk-go/pkg/client"
+	"github.com/vmware/vra-sdk-go/pkg/client/deployments"
+	"github.com/vmware/vra-sdk-go/pkg/client/project"
+	"github.com/vmware/vra-sdk-go/pkg/client/requests"
+	"github.com/vmware/vra-sdk-go/pkg/models"
+)
+
+// importDeploymentInputsJSONPrefix is the prefix of the import id of a deployment whose inputs are configured with
+// inputs_json rather than inputs, as the import cannot read the configuration.
+const importDeploymentInputsJSONPrefix = "inputs_json:"
+
+// resourceDeploymentImportState imports the deployment by id or by project_name/deployment_name, and rebuilds the
+// inputs and the catalog item or cloud template of the deployment from its last successful request, so that the
+// imported deployment matches the configuration it was requested with.
+func resourceDeploymentImportState(_ context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	apiClient := m.(*Client).apiClient
+
+	id, inputsJSON := strings.CutPrefix(d.Id(), importDeploymentInputsJSONPrefix)
+	d.SetId(id)
+
+	if projectName, deploymentName, ok := strings.Cut(d.Id(), "/"); ok {
+		deploymentID, err := getDeploymentIDByProjectAndName(apiClient, projectName, deploymentName)
+		if err != nil {
+			return nil, err
+		}
+		d.SetId(deploymentID)
+	}
+
+	request, err := getLastSuccessfulDeploymentRequest(apiClient, strfmt.UUID(d.Id()))
+	if err != nil {
+		return nil, err
+	}
+	if request != nil {
+		if err := setDeploymentSourceFromRequest(d, apiClient, request); err != nil {
+			return nil, err
+		}
+
+		// The inputs set to their defaults are dropped, as the defaults are not provided by the configuration
+		inputsSchema, err := getDeploymentInputsSchema(d, apiClient)
+		if err != nil {
+			log.Printf("[WARN] Unable to retrieve the inputs schema of deployment %s, the inputs set to their defaults are imported: %s", d.Id(), err)
+		}
+		if err := setDeploymentInputsFromRequest(d, request, inputsSchema, inputsJSON); err != nil {
+			return nil, err
+		}
+	} else {
+		log.Printf("[WARN] No successful request found on deployment %s, its inputs are not imported", d.Id())
+	}
+
+	// The default of simulate is not read from the deployment, so it is set as after an apply, for the imported
+	// deployment to have no changes to plan
+	d.Set("simulate", false)
+	return []*schema.ResourceData{d}, nil
+}
+
+// getDeploymentIDByProjectAndName returns the id of the deployment with the name in the project with the name.
+func getDeploymentIDByProjectAndName(apiClient *client.API, projectName, deploymentName string) (string, error) {
+	if projectName == "" || deploymentName == "" {
+		return "", fmt.Errorf("deployment must be imported by id or by project_name/deployment_name")
+	}
+
+	// The quotes of the name are escaped by doubling them in the OData filter
+	filter := fmt.Sprintf("name eq '%s'", strings.ReplaceAll(projectName, "'", "''"))
+	getProjectsResp, err := apiClient.Project.GetProjects(project.NewGetProjectsParams().WithDollarFilter(withString(filter)))
+	if err != nil {
+		return "", err
+	}
+
+	projects := getProjectsResp.GetPayload().Content
+	if len(projects) == 0 {
+		return "", fmt.Errorf("project %s not found", projectName)
+	}
+	if len(projects) > 1 {
+		return "", fmt.Errorf("found %d projects with name %s", len(projects), projectName)
+	}
+	projectID := *projects[0].ID
+
+	getDeploymentsResp, err := apiClient.Deployments.GetDeploymentsV3UsingGET(
+		deployments.NewGetDeploymentsV3UsingGETParams().
+			WithName(withString(deploymentName)).
+			WithProjects([]string{projectID}).
+			WithAPIVersion(withString(DeploymentsAPIVersion)))
+	if err != nil {
+		return "", err
+	}
+
+	deploymentsFound := getDeploymentsResp.GetPayload().Content
+	if len(deploymentsFound) == 0 {
+		return "", fmt.Errorf("deployment %s not found in project %s", deploymentName, projectName)
+	}
+	if len(deploymentsFound) > 1 {
+		return "", fmt.Errorf("found %d deployments with name %s in project %s", len(deploymentsFound), deploymentName, projectName)
+	}
+
+	return deploymentsFound[0].ID.String(), nil
+}
+
+// getLastSuccessfulDeploymentRequest returns the last successful request of the deployment which requested a catalog
+// item or a cloud template, such as its creation, or which ran its Update day-2 action, or nil if there is none.
+func getLastSuccessfulDeploymentRequest(apiClient *client.API, deploymentUUID strfmt.UUID) (*models.Request, error) {
+	params := requests.NewGetDeploymentRequestsUsingGET2Params().
+		WithDeploymentID(deploymentUUID).
+		WithAPIVersion(withString(DeploymentsAPIVersion)).
+		WithDollarTop(withInt32(DefaultDollarTop))
+
+	// The requests are paged through, since the deployments updated many times have more requests than a page
+	allRequests := make([]*models.Request, 0)
+	for {
+		params = params.WithDollarSkip(withInt32(int32(len(allRequests))))
+		getResp, err := apiClient.Requests.GetDeploymentRequestsUsingGET2(params)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving the requests of deployment %s: %s", deploymentUUID, err)
+		}
+
+		page := getResp.GetPayload()
+		allRequests = append(allRequests, page.Content...)
+		if page.Last || len(page.Content) == 0 || int64(len(allRequests)) >= page.TotalElements {
+			break
+		}
+	}
+
+	var lastRequest *models.Request
+	for _, request := range allRequests {
+		if request.Status != models.RequestStatusSUCCESSFUL {
+			continue
+		}
+		// The requests of the Update action have neither catalog item nor cloud template, but have all the inputs
+		if request.CatalogItemID == "" && request.BlueprintID == "" &&
+			!strings.Contains(strings.ToLower(request.ActionID), UpdateDeploymentActionName) {
+			continue
+		}
+		if lastRequest == nil || deploymentRequestCreatedAt(request).After(deploymentRequestCreatedAt(lastRequest)) {
+			lastRequest = request
+		}
+	}
+
+	return lastRequest, nil
+}
+
+func deploymentRequestCreatedAt(request *models.Request) time.Time {
+	if request.CreatedAt == nil {
+		return time.Time{}
+	}
+	return time.Time(*request.CreatedAt)
+}
+
+// setDeploymentSourceFromRequest sets the catalog item or cloud template of the deployment from the request. The
+// catalog item and the cloud template of the request are in the form UUID:version. The requests of the Update action
+// have neither, which are then read from the deployment.
+func setDeploymentSourceFromRequest(d *schema.ResourceData, apiClient *client.API, request *models.Request) error {
+	if request.CatalogItemID != "" {
+		catalogItemID, catalogItemVersion, _ := strings.Cut(request.CatalogItemID, ":")
+		d.Set("catalog_item_id", catalogItemID)
+		d.Set("catalog_item_version", catalogItemVersion)
+		return nil
+	}
+	if request.BlueprintID != "" {
+		blueprintID, blueprintVersion, _ := strings.Cut(request.BlueprintID, ":")
+		d.Set("blueprint_id", blueprintID)
+		d.Set("blueprint_version", blueprintVersion)
+		return nil
+	}
+
+	getResp, err := apiClient.Deployments.GetDeploymentByIDV3UsingGET(
+		deployments.NewGetDeploymentByIDV3UsingGETParams().
+			WithDeploymentID(strfmt.UUID(d.Id())).
+			WithAPIVersion(withString(DeploymentsAPIVersion)))
+	if err != nil {
+		return fmt.Errorf("error retrieving deployment %s: %s", d.Id(), err)
+	}
+	deployment := getResp.GetPayload()
+	d.Set("blueprint_id", deployment.BlueprintID)
+	d.Set("blueprint_version", deployment.BlueprintVersion)
+	d.Set("catalog_item_id", deployment.CatalogItemID)
+	d.Set("catalog_item_version", deployment.CatalogItemVersion)
+	return nil
+}
+
+// setDeploymentInputsFromRequest sets the inputs of the deployment from the request, in inputs_json if inputsJSON is
+// set. The inputs whose values are the defaults of the inputs schema are not set, since the requests have all the
+// inputs, including those not provided by the user.
+func setDeploymentInputsFromRequest(d *schema.ResourceData, request *models.Request, inputsSchema map[string]interface{}, inputsJSON bool) error {
+	properties, _ := inputsSchema["properties"].(map[string]interface{})
+
+	requestInputs, _ := request.Inputs.(map[string]interface{})
+	userInputs := make(map[string]interface{}, len(requestInputs))
+	for name, value := range requestInputs {
+		// The version of the catalog item is an input of the Update action, not of the deployment
+		if value == nil || name == UpdateDeploymentActionVersionInputName {
+			continue
+		}
+		if property, ok := properties[name].(map[string]interface{}); ok {
+			if defaultValue, ok := property["default"]; ok && inputValuesEqual(value, defaultValue) {
+				continue
+			}
+		}
+		userInputs[name] = value
+	}
+
+	if inputsJSON {
+		value, err := json.Marshal(userInputs)
+		if err != nil {
+			return fmt.Errorf("error encoding deployment inputs_json - error: %#v", err)
+		}
+		d.Set("inputs_json", string(value))
+		return nil
+	}
+
+	inputs := make(map[string]interface{}, len(userInputs))
+	for name, value := range userInputs {
+		inputs[name] = decodeInputValue(name, value, nil)
+	}
+	if err := d.Set("inputs", inputs); err != nil {
+		return fmt.Errorf("error setting deployment inputs - error: %#v", err)
+	}
+	return nil
+}
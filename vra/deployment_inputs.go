@@ -0,0 +1,504 @@
+// © Broadcom. All Rights Reserved.
+// The term “Broadcom” refers to Broadcom Inc. and/or its subsidiaries.
+// SPDX-License-Identifier: MPL-2.0
+
+package vra
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/vra-sdk-go/pkg/client"
+)
+
+// deploymentInputsGetter is implemented by both schema.ResourceData and schema.ResourceDiff, so that the inputs of the
+// deployment are read the same way during plan and apply.
+type deploymentInputsGetter interface {
+	Get(string) interface{}
+	GetOk(string) (interface{}, bool)
+}
+
+// getDeploymentInputs returns the inputs of the deployment provided in inputs, or in inputs_json decoded to their
+// native values, and whether any inputs are provided.
+func getDeploymentInputs(d deploymentInputsGetter) (map[string]interface{}, bool) {
+	if v, ok := d.GetOk("inputs_json"); ok {
+		inputs, err := expandInputsJSON(v.(string))
+		if err != nil {
+			log.Printf("[WARN] Unable to decode inputs_json: %s", err)
+			return nil, false
+		}
+		return inputs, len(inputs) > 0
+	}
+
+	if v, ok := d.GetOk("inputs"); ok {
+		return v.(map[string]interface{}), true
+	}
+	return nil, false
+}
+
+// expandInputsJSON decodes the inputs encoded in a JSON object.
+func expandInputsJSON(inputsJSON string) (map[string]interface{}, error) {
+	inputs := make(map[string]interface{})
+	if inputsJSON == "" {
+		return inputs, nil
+	}
+	if err := json.Unmarshal([]byte(inputsJSON), &inputs); err != nil {
+		return nil, err
+	}
+	return inputs, nil
+}
+
+// validateInputsJSON validates that the inputs are encoded in a JSON object.
+func validateInputsJSON(v interface{}, k string) (ws []string, errors []error) {
+	if _, err := expandInputsJSON(v.(string)); err != nil {
+		errors = append(errors, fmt.Errorf("%q must be a JSON object of the inputs, such as jsonencode({ count = 1 }): %s", k, err))
+	}
+	return
+}
+
+// suppressEquivalentInputsJSON suppresses the diff of inputs which only differ in the order of the keys or the
+// formatting of the JSON document.
+func suppressEquivalentInputsJSON(_, old, new string, _ *schema.ResourceData) bool {
+	oldInputs, err := expandInputsJSON(old)
+	if err != nil {
+		return false
+	}
+	newInputs, err := expandInputsJSON(new)
+	if err != nil {
+		return false
+	}
+	return reflect.DeepEqual(oldInputs, newInputs)
+}
+
+// convertInputValue converts a native input value, such as a value decoded from inputs_json, to the type of the
+// input in the schema of the catalog item or cloud template. Strings are accepted for all the types, as in inputs.
+func convertInputValue(name string, value interface{}, inputType string) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	var err error
+	switch strings.ToLower(inputType) {
+	case "array":
+		switch v := value.(type) {
+		case []interface{}:
+			return v, nil
+		case string:
+			array := make([]interface{}, 0)
+			if err = json.Unmarshal([]byte(v), &array); err == nil {
+				return array, nil
+			}
+		}
+	case "boolean":
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			var b bool
+			if b, err = strconv.ParseBool(v); err == nil {
+				return b, nil
+			}
+		}
+	case "integer":
+		switch v := value.(type) {
+		case float64:
+			if v == math.Trunc(v) {
+				return int(v), nil
+			}
+		case int:
+			return v, nil
+		case string:
+			var i int
+			if i, err = strconv.Atoi(v); err == nil {
+				return i, nil
+			}
+		}
+	case "number":
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case int:
+			return float64(v), nil
+		case string:
+			var f float64
+			if f, err = strconv.ParseFloat(v, 64); err == nil {
+				return f, nil
+			}
+		}
+	case "object":
+		switch v := value.(type) {
+		case map[string]interface{}:
+			return v, nil
+		case string:
+			var object map[string]interface{}
+			if err = json.Unmarshal([]byte(v), &object); err == nil {
+				return object, nil
+			}
+		}
+	case "string":
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case bool, float64, int:
+			return fmt.Sprint(v), nil
+		}
+	default:
+		return value, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert input '%v' value '%v' into type '%s'. %s", name, value, inputType, err)
+	}
+	return nil, fmt.Errorf("cannot convert input '%v' value '%v' into type '%s'", name, value, inputType)
+}
+
+// flattenInputsJSON returns the inputs provided by the user in inputs_json, with the values of the deployment. The
+// values provided by the user are kept when they are the same as the values of the deployment once converted to the
+// types of the inputs, so that only the inputs changed outside of Terraform are reported as changes.
+func flattenInputsJSON(allInputs, userInputs map[string]interface{}, inputTypesMap map[string]string) (string, error) {
+	inputs := make(map[string]interface{})
+	for name, value := range userInputs {
+		deploymentValue, ok := allInputs[name]
+		if !ok {
+			continue
+		}
+
+		inputs[name] = deploymentValue
+		if inputValueUnchanged(name, value, deploymentValue, inputTypesMap) {
+			inputs[name] = value
+		}
+	}
+
+	inputsJSON, err := json.Marshal(inputs)
+	if err != nil {
+		return "", err
+	}
+	return string(inputsJSON), nil
+}
+
+// inputValueUnchanged returns whether the configured value of the input is equivalent to its value on the deployment,
+// once converted to the type of the input in the inputs schema.
+func inputValueUnchanged(name string, value, deploymentValue interface{}, inputTypesMap map[string]string) bool {
+	if t, ok := inputTypesMap[name]; ok {
+		converted, err := convertInputValue(name, value, t)
+		return err == nil && inputValuesEqual(converted, deploymentValue)
+	}
+	if inputValuesEqual(value, deploymentValue) {
+		return true
+	}
+	// Without type information, the values are compared as they are stored in the inputs map
+	s, ok := value.(string)
+	return ok && s == decodeInputValue(name, deploymentValue, inputTypesMap)
+}
+
+// inputValuesEqual returns whether the input values are the same once encoded in JSON, so that the numbers are
+// compared regardless of their Go type.
+func inputValuesEqual(a, b interface{}) bool {
+	aJSON, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	bJSON, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+
+	var aValue, bValue interface{}
+	if json.Unmarshal(aJSON, &aValue) != nil || json.Unmarshal(bJSON, &bValue) != nil {
+		return false
+	}
+	return reflect.DeepEqual(aValue, bValue)
+}
+
+// inputViolation is an input of the deployment which is not valid against the inputs schema.
+type inputViolation struct {
+	name    string
+	message string
+}
+
+// resourceDeploymentInputsCustomizeDiff validates the inputs of the deployment against the inputs schema of the
+// catalog item or cloud template during plan, so that invalid inputs are reported before the deployment is requested.
+func resourceDeploymentInputsCustomizeDiff(_ context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() != "" && !d.HasChanges("blueprint_id", "blueprint_version", "catalog_item_id", "catalog_item_version", "inputs", "inputs_json") {
+		return nil
+	}
+
+	for _, key := range []string{"blueprint_id", "blueprint_version", "catalog_item_id", "catalog_item_version", "inputs_json"} {
+		if deploymentPlanValueUnknown(d, key) {
+			return nil
+		}
+	}
+
+	attribute := "inputs"
+	var inputs map[string]interface{}
+	if v, ok := d.GetOk("inputs_json"); ok {
+		attribute = "inputs_json"
+		var err error
+		if inputs, err = expandInputsJSON(v.(string)); err != nil {
+			return nil
+		}
+	} else if inputs = getKnownDeploymentInputs(d); inputs == nil {
+		return nil
+	}
+
+	apiClient := m.(*Client).apiClient
+	inputsSchema, err := getDeploymentInputsSchema(d, apiClient)
+	if err != nil {
+		// The error is reported when the deployment is requested
+		log.Printf("[WARN] Unable to validate the inputs of vra_deployment %s: %s", d.Get("name"), err)
+		return nil
+	}
+	if inputsSchema == nil {
+		return nil
+	}
+
+	violations := validateInputs(inputs, inputsSchema, d.Id() == "")
+	return diagnosticsError(attribute, inputViolationsDiagnostics(attribute, violations))
+}
+
+// validateDeploymentInputs validates the inputs of the deployment against the inputs schema of its catalog item or
+// cloud template before they are submitted, since the inputs are not validated during plan when they are not known
+// yet.
+func validateDeploymentInputs(d *schema.ResourceData, apiClient *client.API, checkRequired bool) diag.Diagnostics {
+	inputs, ok := getDeploymentInputs(d)
+	if !ok && !checkRequired {
+		return nil
+	}
+
+	inputsSchema, err := getDeploymentInputsSchema(d, apiClient)
+	if err != nil {
+		// The error is reported when the deployment is requested
+		log.Printf("[WARN] Unable to validate the inputs of vra_deployment %s: %s", d.Get("name"), err)
+		return nil
+	}
+	if inputsSchema == nil {
+		return nil
+	}
+
+	attribute := "inputs"
+	if _, ok := d.GetOk("inputs_json"); ok {
+		attribute = "inputs_json"
+	}
+	return inputViolationsDiagnostics(attribute, validateInputs(inputs, inputsSchema, checkRequired))
+}
+
+// getKnownDeploymentInputs returns the inputs of the deployment provided in inputs, the inputs whose values are not
+// known yet having nil values, or nil if the inputs are not known yet.
+func getKnownDeploymentInputs(d *schema.ResourceDiff) map[string]interface{} {
+	if !d.NewValueKnown("inputs") {
+		return nil
+	}
+
+	inputs := make(map[string]interface{})
+	for name, value := range d.Get("inputs").(map[string]interface{}) {
+		inputs[name] = value
+	}
+
+	rawInputs, diags := d.GetRawConfigAt(cty.GetAttrPath("inputs"))
+	if diags.HasError() || !rawInputs.IsKnown() || rawInputs.IsNull() {
+		return inputs
+	}
+	for it := rawInputs.ElementIterator(); it.Next(); {
+		key, value := it.Element()
+		if !value.IsKnown() {
+			inputs[key.AsString()] = nil
+		}
+	}
+	return inputs
+}
+
+// getDeploymentInputsSchema returns the JSON schema of the inputs of the catalog item or cloud template of the
+// deployment, or nil if the deployment is requested with an inline cloud template or without any cloud template.
+func getDeploymentInputsSchema(d deploymentInputsGetter, apiClient *client.API) (map[string]interface{}, error) {
+	if v, ok := d.GetOk("catalog_item_id"); ok {
+		return getCatalogItemInputsSchema(apiClient, v.(string), d.Get("catalog_item_version").(string))
+	}
+
+	blueprintID := d.Get("blueprint_id").(string)
+	if _, ok := d.GetOk("blueprint_content"); ok || blueprintID == "" || blueprintID == "inline-blueprint" {
+		return nil, nil
+	}
+
+	blueprintInputsSchema, err := getBlueprintInputsSchema(apiClient, blueprintID, d.Get("blueprint_version").(string))
+	if err != nil || blueprintInputsSchema == nil {
+		return nil, err
+	}
+
+	// The blueprint inputs schema is converted to the JSON schema of the catalog items
+	schemaJSON, err := json.Marshal(blueprintInputsSchema)
+	if err != nil {
+		return nil, err
+	}
+	inputsSchema := make(map[string]interface{})
+	if err := json.Unmarshal(schemaJSON, &inputsSchema); err != nil {
+		return nil, err
+	}
+	return inputsSchema, nil
+}
+
+// validateInputs validates the inputs against the JSON schema of the inputs, and returns the violations sorted by the
+// name of the inputs. The inputs with nil values are not known yet and are only checked to be provided.
+func validateInputs(inputs map[string]interface{}, inputsSchema map[string]interface{}, checkRequired bool) []inputViolation {
+	violations := make([]inputViolation, 0)
+	properties, _ := inputsSchema["properties"].(map[string]interface{})
+
+	names := make([]string, 0, len(inputs))
+	for name := range inputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		property, ok := properties[name].(map[string]interface{})
+		if !ok || inputs[name] == nil {
+			continue
+		}
+		if message := validateInputValue(name, inputs[name], property); message != "" {
+			violations = append(violations, inputViolation{name: name, message: message})
+		}
+	}
+
+	if checkRequired {
+		required, _ := inputsSchema["required"].([]interface{})
+		for _, r := range required {
+			name, _ := r.(string)
+			if _, ok := inputs[name]; ok {
+				continue
+			}
+			if property, ok := properties[name].(map[string]interface{}); ok && property["default"] != nil {
+				continue
+			}
+			violations = append(violations, inputViolation{name: name, message: "is required"})
+		}
+	}
+	return violations
+}
+
+// validateInputValue validates the value of the input against the schema of the input, and returns the violation
+// message or "" if the value is valid.
+func validateInputValue(name string, value interface{}, property map[string]interface{}) string {
+	inputType, _ := property["type"].(string)
+	converted, err := convertInputValue(name, value, inputType)
+	if err != nil {
+		return fmt.Sprintf("must be of type %s", inputType)
+	}
+
+	if enum, ok := property["enum"].([]interface{}); ok && len(enum) > 0 {
+		allowed := make([]string, 0, len(enum))
+		for _, e := range enum {
+			if inputValuesEqual(converted, e) {
+				allowed = nil
+				break
+			}
+			allowed = append(allowed, fmt.Sprintf("%v", e))
+		}
+		if allowed != nil {
+			return fmt.Sprintf("must be one of %s", strings.Join(allowed, ", "))
+		}
+	}
+
+	switch v := converted.(type) {
+	case int:
+		return validateInputNumber(float64(v), property)
+	case float64:
+		return validateInputNumber(v, property)
+	case string:
+		if minLength, ok := inputSchemaNumber(property, "minLength"); ok && float64(len(v)) < minLength {
+			return fmt.Sprintf("must be at least %v characters long", minLength)
+		}
+		if maxLength, ok := inputSchemaNumber(property, "maxLength"); ok && float64(len(v)) > maxLength {
+			return fmt.Sprintf("must be at most %v characters long", maxLength)
+		}
+		if pattern, ok := property["pattern"].(string); ok && pattern != "" {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				log.Printf("[DEBUG] Unable to validate input %s against pattern %s: %s", name, pattern, err)
+			} else if !re.MatchString(v) {
+				return fmt.Sprintf("must match the pattern %s", pattern)
+			}
+		}
+	case []interface{}:
+		if minItems, ok := inputSchemaNumber(property, "minItems"); ok && float64(len(v)) < minItems {
+			return fmt.Sprintf("must have at least %v items", minItems)
+		}
+		if maxItems, ok := inputSchemaNumber(property, "maxItems"); ok && float64(len(v)) > maxItems {
+			return fmt.Sprintf("must have at most %v items", maxItems)
+		}
+	}
+	return ""
+}
+
+func validateInputNumber(value float64, property map[string]interface{}) string {
+	if minimum, ok := inputSchemaNumber(property, "minimum"); ok && value < minimum {
+		return fmt.Sprintf("must be at least %v", minimum)
+	}
+	if maximum, ok := inputSchemaNumber(property, "maximum"); ok && value > maximum {
+		return fmt.Sprintf("must be at most %v", maximum)
+	}
+	return ""
+}
+
+// inputSchemaNumber returns the number of the keyword of the input schema, and whether it is set.
+func inputSchemaNumber(property map[string]interface{}, keyword string) (float64, bool) {
+	switch v := property[keyword].(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		// The schemas are decoded with json.Number by the API client
+		if f, err := v.Float64(); err == nil {
+			return f, true
+		}
+	}
+	return 0, false
+}
+
+// inputViolationsDiagnostics returns a diagnostic for each violation, scoped to the input when the inputs are
+// provided in inputs, and to the attribute of the inputs otherwise.
+func inputViolationsDiagnostics(attribute string, violations []inputViolation) diag.Diagnostics {
+	var diags diag.Diagnostics
+	for _, violation := range violations {
+		path := cty.GetAttrPath(attribute)
+		if attribute == "inputs" {
+			path = path.IndexString(violation.name)
+		}
+		diags = append(diags, diag.Diagnostic{
+			Severity:      diag.Error,
+			Summary:       fmt.Sprintf("invalid input %q: %s", violation.name, violation.message),
+			AttributePath: path,
+		})
+	}
+	return diags
+}
+
+// diagnosticsError returns the error of the diagnostics for CustomizeDiff, which the SDK reports as a single
+// diagnostic. The error is scoped to the attribute of a single diagnostic, and to the attribute of the inputs for
+// several diagnostics.
+func diagnosticsError(attribute string, diags diag.Diagnostics) error {
+	switch len(diags) {
+	case 0:
+		return nil
+	case 1:
+		return diags[0].AttributePath.NewErrorf("%s", diags[0].Summary)
+	}
+
+	summaries := make([]string, 0, len(diags))
+	for _, d := range diags {
+		summaries = append(summaries, d.Summary)
+	}
+	return cty.GetAttrPath(attribute).NewErrorf("%s", strings.Join(summaries, ", "))
+}
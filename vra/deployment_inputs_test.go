@@ -0,0 +1,250 @@
+// © Broadcom. All Rights Reserved.
+// The term “Broadcom” refers to Broadcom Inc. and/or its subsidiaries.
+// SPDX-License-Identifier: MPL-2.0
+
+package vra
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+func TestConvertInputValue(t *testing.T) {
+	var tests = []struct {
+		value     interface{}
+		inputType string
+		expected  interface{}
+		err       bool
+	}{
+		{float64(2), "integer", 2, false},
+		{"2", "integer", 2, false},
+		{2.5, "integer", nil, true},
+		{"two", "integer", nil, true},
+		{float64(2), "number", float64(2), false},
+		{"2.5", "number", 2.5, false},
+		{true, "boolean", true, false},
+		{"false", "boolean", false, false},
+		{float64(1), "boolean", nil, true},
+		{float64(5), "string", "5", false},
+		{"text", "string", "text", false},
+		{[]interface{}{"a", "b"}, "string", nil, true},
+		{[]interface{}{"a", "b"}, "array", []interface{}{"a", "b"}, false},
+		{`["a","b"]`, "array", []interface{}{"a", "b"}, false},
+		{map[string]interface{}{"key": "value"}, "array", nil, true},
+		{map[string]interface{}{"key": "value"}, "object", map[string]interface{}{"key": "value"}, false},
+		{`{"key":"value"}`, "object", map[string]interface{}{"key": "value"}, false},
+		{[]interface{}{"a"}, "unknown", []interface{}{"a"}, false},
+	}
+
+	for _, test := range tests {
+		actual, err := convertInputValue("input", test.value, test.inputType)
+		if test.err {
+			if err == nil {
+				t.Errorf("convertInputValue expected an error for %#v of type %s, actual %#v", test.value, test.inputType, actual)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("convertInputValue expected no error for %#v of type %s, actual %s", test.value, test.inputType, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("convertInputValue expected %#v for %#v of type %s, actual %#v", test.expected, test.value, test.inputType, actual)
+		}
+	}
+}
+
+func TestSuppressEquivalentInputsJSON(t *testing.T) {
+	var tests = []struct {
+		old      string
+		new      string
+		expected bool
+	}{
+		{`{"a":1,"b":[1,2]}`, `{ "b": [1, 2], "a": 1 }`, true},
+		{`{"a":{"x":1,"y":2}}`, `{"a":{"y":2,"x":1}}`, true},
+		{`{"a":1}`, `{"a":2}`, false},
+		{`{"a":[1,2]}`, `{"a":[2,1]}`, false},
+		{`{"a":1}`, `not json`, false},
+		{``, `{}`, true},
+	}
+
+	for _, test := range tests {
+		if actual := suppressEquivalentInputsJSON("inputs_json", test.old, test.new, nil); actual != test.expected {
+			t.Errorf("suppressEquivalentInputsJSON expected %t for %s and %s, actual %t", test.expected, test.old, test.new, actual)
+		}
+	}
+}
+
+func TestFlattenInputsJSON(t *testing.T) {
+	allInputs := map[string]interface{}{
+		"count":   float64(2),
+		"flag":    true,
+		"name":    "changed",
+		"tags":    []interface{}{"a", "b"},
+		"default": "server default",
+	}
+	inputTypesMap := map[string]string{
+		"count": "integer",
+		"flag":  "boolean",
+		"name":  "string",
+		"tags":  "array",
+	}
+
+	var tests = []struct {
+		userInputs map[string]interface{}
+		expected   string
+	}{
+		{map[string]interface{}{"count": float64(2), "tags": []interface{}{"a", "b"}}, `{"count":2,"tags":["a","b"]}`},
+		{map[string]interface{}{"count": "2", "flag": "true"}, `{"count":"2","flag":"true"}`},
+		{map[string]interface{}{"name": "original"}, `{"name":"changed"}`},
+		{map[string]interface{}{"tags": []interface{}{"b", "a"}}, `{"tags":["a","b"]}`},
+		{map[string]interface{}{"missing": "value"}, `{}`},
+		{map[string]interface{}{"default": "server default"}, `{"default":"server default"}`},
+	}
+
+	for _, test := range tests {
+		actual, err := flattenInputsJSON(allInputs, test.userInputs, inputTypesMap)
+		if err != nil {
+			t.Errorf("flattenInputsJSON expected no error for %#v, actual %s", test.userInputs, err)
+			continue
+		}
+		if actual != test.expected {
+			t.Errorf("flattenInputsJSON expected %s for %#v, actual %s", test.expected, test.userInputs, actual)
+		}
+	}
+}
+
+func TestUpdateUserInputs(t *testing.T) {
+	allInputs := map[string]interface{}{
+		"count":   float64(2),
+		"size":    1.5,
+		"flag":    true,
+		"name":    "changed",
+		"tags":    []interface{}{"a", "b"},
+		"default": "server default",
+	}
+	inputTypesMap := map[string]string{
+		"count": "integer",
+		"size":  "number",
+		"flag":  "boolean",
+		"name":  "string",
+		"tags":  "array",
+	}
+
+	var tests = []struct {
+		userInputs map[string]interface{}
+		expected   map[string]interface{}
+	}{
+		{map[string]interface{}{"count": "2", "size": "1.50", "flag": "True"}, map[string]interface{}{"count": "2", "size": "1.50", "flag": "True"}},
+		{map[string]interface{}{"count": "3", "name": "original"}, map[string]interface{}{"count": "2", "name": "changed"}},
+		{map[string]interface{}{"tags": `["a", "b"]`}, map[string]interface{}{"tags": `["a", "b"]`}},
+		{map[string]interface{}{"tags": `["b"]`}, map[string]interface{}{"tags": `["a","b"]`}},
+		{map[string]interface{}{"missing": "value"}, map[string]interface{}{}},
+		{map[string]interface{}{"default": "server default"}, map[string]interface{}{"default": "server default"}},
+	}
+
+	for _, test := range tests {
+		actual := updateUserInputs(allInputs, test.userInputs, inputTypesMap)
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("updateUserInputs expected %#v for %#v, actual %#v", test.expected, test.userInputs, actual)
+		}
+	}
+}
+
+func TestValidateInputs(t *testing.T) {
+	inputsSchema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"count":  map[string]interface{}{"type": "integer", "minimum": float64(1), "maximum": float64(5)},
+			"flavor": map[string]interface{}{"type": "string", "enum": []interface{}{"small", "medium"}},
+			"name":   map[string]interface{}{"type": "string", "pattern": "^[a-z]+$", "maxLength": float64(8)},
+			"tags":   map[string]interface{}{"type": "array", "minItems": float64(1)},
+			"flag":   map[string]interface{}{"type": "boolean"},
+			"image":  map[string]interface{}{"type": "string", "default": "ubuntu"},
+		},
+		"required": []interface{}{"count", "image"},
+	}
+
+	var tests = []struct {
+		inputs        map[string]interface{}
+		checkRequired bool
+		expected      []inputViolation
+	}{
+		{map[string]interface{}{"count": "2", "flavor": "small", "name": "web", "tags": `["a"]`, "flag": "true"}, true, []inputViolation{}},
+		{map[string]interface{}{"count": float64(2), "tags": []interface{}{"a"}, "flag": true}, true, []inputViolation{}},
+		{map[string]interface{}{"count": "0"}, true, []inputViolation{{"count", "must be at least 1"}}},
+		{map[string]interface{}{"count": float64(6)}, true, []inputViolation{{"count", "must be at most 5"}}},
+		{map[string]interface{}{"count": "two"}, true, []inputViolation{{"count", "must be of type integer"}}},
+		{map[string]interface{}{"count": "1", "flavor": "large"}, true, []inputViolation{{"flavor", "must be one of small, medium"}}},
+		{map[string]interface{}{"count": "1", "name": "Web1"}, true, []inputViolation{{"name", "must match the pattern ^[a-z]+$"}}},
+		{map[string]interface{}{"count": "1", "name": "webserver"}, true, []inputViolation{{"name", "must be at most 8 characters long"}}},
+		{map[string]interface{}{"count": "1", "tags": []interface{}{}}, true, []inputViolation{{"tags", "must have at least 1 items"}}},
+		{map[string]interface{}{"count": "1", "flag": "yes"}, true, []inputViolation{{"flag", "must be of type boolean"}}},
+		{map[string]interface{}{"flavor": "small"}, true, []inputViolation{{"count", "is required"}}},
+		{map[string]interface{}{"flavor": "small"}, false, []inputViolation{}},
+		{map[string]interface{}{"count": nil, "flavor": "tiny"}, true, []inputViolation{{"flavor", "must be one of small, medium"}}},
+		{map[string]interface{}{"count": "1", "unknown": "value"}, true, []inputViolation{}},
+	}
+
+	for _, test := range tests {
+		actual := validateInputs(test.inputs, inputsSchema, test.checkRequired)
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("validateInputs expected %v for %#v, actual %v", test.expected, test.inputs, actual)
+		}
+	}
+}
+
+func TestInputViolationsDiagnostics(t *testing.T) {
+	var tests = []struct {
+		attribute  string
+		violations []inputViolation
+		paths      []cty.Path
+		summaries  []string
+	}{
+		{"inputs", []inputViolation{{"count", "must be at least 1"}}, []cty.Path{cty.GetAttrPath("inputs").IndexString("count")}, []string{`invalid input "count": must be at least 1`}},
+		{"inputs", []inputViolation{{"count", "is required"}}, []cty.Path{cty.GetAttrPath("inputs").IndexString("count")}, []string{`invalid input "count": is required`}},
+		{"inputs_json", []inputViolation{{"count", "must be at least 1"}}, []cty.Path{cty.GetAttrPath("inputs_json")}, []string{`invalid input "count": must be at least 1`}},
+		{"inputs", []inputViolation{{"count", "must be at least 1"}, {"flavor", "must be one of small"}}, []cty.Path{cty.GetAttrPath("inputs").IndexString("count"), cty.GetAttrPath("inputs").IndexString("flavor")}, []string{`invalid input "count": must be at least 1`, `invalid input "flavor": must be one of small`}},
+	}
+
+	for _, test := range tests {
+		diags := inputViolationsDiagnostics(test.attribute, test.violations)
+		if len(diags) != len(test.violations) {
+			t.Errorf("inputViolationsDiagnostics expected %d diagnostics for %v, actual %v", len(test.violations), test.violations, diags)
+			continue
+		}
+		for i, d := range diags {
+			if d.Severity != diag.Error || !d.AttributePath.Equals(test.paths[i]) || d.Summary != test.summaries[i] {
+				t.Errorf("inputViolationsDiagnostics expected %q at %#v, actual %q at %#v", test.summaries[i], test.paths[i], d.Summary, d.AttributePath)
+			}
+		}
+	}
+
+	if diags := inputViolationsDiagnostics("inputs", []inputViolation{}); len(diags) != 0 {
+		t.Errorf("inputViolationsDiagnostics expected no diagnostics without violations, actual %v", diags)
+	}
+}
+
+func TestDiagnosticsError(t *testing.T) {
+	diags := inputViolationsDiagnostics("inputs", []inputViolation{{"count", "must be at least 1"}})
+	var pathErr cty.PathError
+	if err := diagnosticsError("inputs", diags); !errors.As(err, &pathErr) || !pathErr.Path.Equals(cty.GetAttrPath("inputs").IndexString("count")) {
+		t.Errorf("diagnosticsError expected an error at the input, actual %#v", err)
+	}
+
+	diags = inputViolationsDiagnostics("inputs", []inputViolation{{"count", "must be at least 1"}, {"flavor", "must be one of small"}})
+	err := diagnosticsError("inputs", diags)
+	if !errors.As(err, &pathErr) || !pathErr.Path.Equals(cty.GetAttrPath("inputs")) {
+		t.Errorf("diagnosticsError expected an error at the inputs, actual %#v", err)
+	} else if expected := `invalid input "count": must be at least 1, invalid input "flavor": must be one of small`; err.Error() != expected {
+		t.Errorf("diagnosticsError expected %q, actual %q", expected, err.Error())
+	}
+
+	if err := diagnosticsError("inputs", nil); err != nil {
+		t.Errorf("diagnosticsError expected no error without diagnostics, actual %s", err)
+	}
+}
@@ -0,0 +1,200 @@
+// © Broadcom. All Rights Reserved.
+// The term “Broadcom” refers to Broadcom Inc. and/or its subsidiaries.
+// SPDX-License-Identifier: MPL-2.0
+
+package vra
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/vra-sdk-go/pkg/client"
+	"github.com/vmware/vra-sdk-go/pkg/client/policies"
+	"github.com/vmware/vra-sdk-go/pkg/models"
+)
+
+// Name of the input of the ChangeLease deployment action
+const ChangeLeaseDeploymentActionInputName = "Lease Expiration Date"
+
+// resourceDeploymentLeaseCustomizeDiff marks the lease expiration date of the deployment as known after apply when
+// the lease is changed with lease_days, so that the change is planned as an in-place update.
+func resourceDeploymentLeaseCustomizeDiff(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if d.Get("lease_days").(int) > 0 && d.HasChange("lease_days") {
+		return d.SetNewComputed("lease_expire_at")
+	}
+	return nil
+}
+
+// suppressEquivalentLeaseExpireAt suppresses the diff of lease expiration dates which are the same time in different
+// formats, since the lease expiration date is returned by the API in UTC with milliseconds.
+func suppressEquivalentLeaseExpireAt(_, old, new string, _ *schema.ResourceData) bool {
+	oldTime, err := time.Parse(time.RFC3339, old)
+	if err != nil {
+		return false
+	}
+	newTime, err := time.Parse(time.RFC3339, new)
+	if err != nil {
+		return false
+	}
+	return oldTime.Equal(newTime)
+}
+
+// validateLeaseExpireAt validates that the lease expiration date is a RFC 3339 timestamp.
+func validateLeaseExpireAt(v interface{}, k string) (ws []string, errors []error) {
+	if _, err := time.Parse(time.RFC3339, v.(string)); err != nil {
+		errors = append(errors, fmt.Errorf("%q must be a RFC 3339 timestamp, such as 2025-12-31T23:59:59Z: %s", k, err))
+	}
+	return
+}
+
+// getDeploymentLeaseExpireAt returns the lease expiration date requested in the configuration, either with
+// lease_days relative to now or with lease_expire_at, and whether a lease is requested.
+func getDeploymentLeaseExpireAt(d *schema.ResourceData) (time.Time, bool) {
+	if v, ok := d.GetOk("lease_days"); ok {
+		return time.Now().UTC().AddDate(0, 0, v.(int)), true
+	}
+
+	// The lease expiration date is computed, so it is only requested when it is configured
+	value, diags := d.GetRawConfigAt(cty.GetAttrPath("lease_expire_at"))
+	if diags.HasError() || !value.IsKnown() || value.IsNull() {
+		return time.Time{}, false
+	}
+	leaseExpireAt, err := time.Parse(time.RFC3339, value.AsString())
+	if err != nil {
+		return time.Time{}, false
+	}
+	return leaseExpireAt.UTC(), true
+}
+
+// deploymentLeaseChanged returns whether the lease of the deployment is changed by the configuration.
+func deploymentLeaseChanged(d *schema.ResourceData) bool {
+	if d.Get("lease_days").(int) > 0 {
+		return d.HasChange("lease_days")
+	}
+	_, ok := getDeploymentLeaseExpireAt(d)
+	return ok && d.HasChange("lease_expire_at")
+}
+
+// validateDeploymentLease validates the lease expiration date against the maximums of the lease policies of the
+// project of the deployment. The lease policies whose criteria cannot be evaluated are not validated, they are
+// enforced by the API when the lease is changed.
+func validateDeploymentLease(apiClient *client.API, projectID string, createdAt time.Time, leaseExpireAt time.Time) error {
+	leasePolicies, err := getLeasePolicies(apiClient)
+	if err != nil {
+		return fmt.Errorf("error retrieving lease policies: %s", err)
+	}
+
+	now := time.Now().UTC()
+	for _, policy := range leasePolicies {
+		if !isLeasePolicyApplicable(policy, projectID) {
+			continue
+		}
+
+		var definition PolicyLeaseDefinition
+		if err := policyDefinitionConvert(policy.Definition, &definition); err != nil {
+			return err
+		}
+
+		var violation string
+		if definition.LeaseTermMax > 0 && leaseExpireAt.After(now.AddDate(0, 0, definition.LeaseTermMax)) {
+			violation = fmt.Sprintf("the maximum lease of %d days", definition.LeaseTermMax)
+		} else if definition.LeaseTotalTermMax > 0 && leaseExpireAt.After(createdAt.AddDate(0, 0, definition.LeaseTotalTermMax)) {
+			violation = fmt.Sprintf("the maximum total lease of %d days since the deployment was created", definition.LeaseTotalTermMax)
+		}
+		if violation == "" {
+			continue
+		}
+
+		if policy.EnforcementType != "HARD" {
+			log.Printf("[WARN] Lease expiration date %s exceeds %s of %s lease policy %s", leaseExpireAt.Format(time.RFC3339), violation, policy.EnforcementType, policy.Name)
+			continue
+		}
+		return fmt.Errorf("lease expiration date %s exceeds %s of lease policy %s", leaseExpireAt.Format(time.RFC3339), violation, policy.Name)
+	}
+	return nil
+}
+
+// getLeasePolicies returns all the lease policies, which are paged through since there may be more policies than the
+// maximum number of policies in a page.
+func getLeasePolicies(apiClient *client.API) ([]*models.Policy, error) {
+	params := policies.NewGetPoliciesUsingGET5Params().
+		WithTypeID(PolicyLeaseTypeID).
+		WithExpandDefinition(withBool(true)).
+		WithDollarTop(withInt32(DefaultDollarTop))
+
+	leasePolicies := make([]*models.Policy, 0)
+	for {
+		params = params.WithDollarSkip(withInt32(int32(len(leasePolicies))))
+		getResp, err := apiClient.Policies.GetPoliciesUsingGET5(params)
+		if err != nil {
+			return nil, err
+		}
+
+		page := getResp.GetPayload()
+		leasePolicies = append(leasePolicies, page.Content...)
+		if page.Last || len(page.Content) == 0 || int64(len(leasePolicies)) >= page.TotalElements {
+			return leasePolicies, nil
+		}
+	}
+}
+
+// isLeasePolicyApplicable returns whether the lease policy applies to the deployments of the project. The policies
+// of the project and the policies of the organization without any criteria are applicable.
+func isLeasePolicyApplicable(policy *models.Policy, projectID string) bool {
+	if policy.Criteria != nil && len(policy.Criteria.MatchExpression) > 0 {
+		log.Printf("[DEBUG] Lease policy %s has deployment criteria, skipping its validation", policy.Name)
+		return false
+	}
+	if policy.ProjectID != "" {
+		return policy.ProjectID == projectID
+	}
+	return policy.ScopeCriteria == nil || len(policy.ScopeCriteria.MatchExpression) == 0
+}
+
+func runChangeLeaseDeploymentAction(ctx context.Context, d *schema.ResourceData, apiClient *client.API, deploymentUUID strfmt.UUID, leaseExpireAt time.Time) error {
+	log.Printf("Noticed changes to lease. Starting to change deployment lease to %s", leaseExpireAt.Format(time.RFC3339))
+
+	createdAt, err := time.Parse(time.RFC3339, d.Get("created_at").(string))
+	if err != nil {
+		createdAt = time.Now().UTC()
+	}
+	if err := validateDeploymentLease(apiClient, d.Get("project_id").(string), createdAt, leaseExpireAt); err != nil {
+		return err
+	}
+
+	// Get the deployment actionID for Change Lease
+	isActionValid, actionID, err := getDeploymentDay2ActionID(apiClient, deploymentUUID, ChangeLeaseDeploymentActionName)
+	if err != nil {
+		return fmt.Errorf("noticed changes to lease. But, %s", err.Error())
+	}
+
+	if !isActionValid {
+		return fmt.Errorf("noticed changes to lease, but 'Change Lease' action is not found or supported")
+	}
+
+	actionInputs := make(map[string]interface{})
+	actionInputs[ChangeLeaseDeploymentActionInputName] = strfmt.DateTime(leaseExpireAt).String()
+
+	actionInputTypesMap, err := getDeploymentActionInputTypesMap(apiClient, deploymentUUID, actionID)
+	if err != nil {
+		return err
+	}
+
+	inputs, err := getInputsByType(actionInputs, actionInputTypesMap)
+	if err != nil {
+		return fmt.Errorf("unable to create action inputs for %v. %v", actionID, err.Error())
+	}
+
+	reason := "Updated deployment lease from vRA provider for Terraform."
+	if err := runAction(ctx, d, apiClient, deploymentUUID, actionID, inputs, reason); err != nil {
+		return err
+	}
+
+	log.Printf("Finished changing lease for vra_deployment %s to %s", d.Get("name").(string), leaseExpireAt.Format(time.RFC3339))
+	return nil
+}
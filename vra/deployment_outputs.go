@@ -0,0 +1,79 @@
+// © Broadcom. All Rights Reserved.
+// The term “Broadcom” refers to Broadcom Inc. and/or its subsidiaries.
+// SPDX-License-Identifier: MPL-2.0
+
+package vra
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+	"github.com/vmware/vra-sdk-go/pkg/client"
+	"github.com/vmware/vra-sdk-go/pkg/client/deployments"
+	"github.com/vmware/vra-sdk-go/pkg/models"
+)
+
+// deploymentWithOutputs is the deployment returned by the Deployment API, with the outputs of its cloud template which
+// are not part of the deployment model of the SDK.
+type deploymentWithOutputs struct {
+	models.Deployment
+	Outputs map[string]interface{} `json:"outputs"`
+}
+
+// getDeploymentWithOutputs returns the deployment and the outputs of its cloud template. It sends the same request as
+// GetDeploymentByIDV3UsingGET of the SDK, whose errors it returns, but keeps the outputs of the response, which are
+// empty when the API does not return them.
+func getDeploymentWithOutputs(apiClient *client.API, deploymentID string, expand []string) (*models.Deployment, map[string]interface{}, error) {
+	params := deployments.NewGetDeploymentByIDV3UsingGETParams().
+		WithDeploymentID(strfmt.UUID(deploymentID)).
+		WithExpand(expand).
+		WithAPIVersion(withString(DeploymentsAPIVersion))
+
+	result, err := apiClient.Transport.Submit(&runtime.ClientOperation{
+		ID:                 "getDeploymentByIdV3UsingGET",
+		Method:             http.MethodGet,
+		PathPattern:        "/deployment/api/deployments/{deploymentId}",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{"https"},
+		Params:             params,
+		Reader: runtime.ClientResponseReaderFunc(func(response runtime.ClientResponse, consumer runtime.Consumer) (interface{}, error) {
+			if response.Code() != http.StatusOK {
+				return (&deployments.GetDeploymentByIDV3UsingGETReader{}).ReadResponse(response, consumer)
+			}
+			var deployment deploymentWithOutputs
+			if err := consumer.Consume(response.Body(), &deployment); err != nil {
+				return nil, err
+			}
+			return &deployment, nil
+		}),
+		Context: params.Context,
+		Client:  params.HTTPClient,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	deployment := result.(*deploymentWithOutputs)
+	return &deployment.Deployment, deployment.Outputs, nil
+}
+
+// flattenDeploymentOutputs returns the outputs of the deployment with their values encoded in JSON, so that they keep
+// their types and can be decoded with jsondecode.
+func flattenDeploymentOutputs(outputs map[string]interface{}) map[string]string {
+	flattened := make(map[string]string, len(outputs))
+	for name, value := range outputs {
+		valueJSON, err := json.Marshal(value)
+		if err != nil {
+			log.Printf("[WARN] Failed to marshal output '%s' to JSON: %v", name, err)
+			flattened[name] = fmt.Sprint(value)
+			continue
+		}
+		flattened[name] = string(valueJSON)
+	}
+	return flattened
+}
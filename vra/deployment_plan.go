@@ -0,0 +1,281 @@
+// © Broadcom. All Rights Reserved.
+// The term “Broadcom” refers to Broadcom Inc. and/or its subsidiaries.
+// SPDX-License-Identifier: MPL-2.0
+
+package vra
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/vra-sdk-go/pkg/client"
+	"github.com/vmware/vra-sdk-go/pkg/client/blueprint_requests"
+	"github.com/vmware/vra-sdk-go/pkg/client/catalog_items"
+	"github.com/vmware/vra-sdk-go/pkg/models"
+)
+
+// Status of the blueprint requests
+const (
+	BlueprintRequestStatusCreated   = "CREATED"
+	BlueprintRequestStatusStarted   = "STARTED"
+	BlueprintRequestStatusFinished  = "FINISHED"
+	BlueprintRequestStatusFailed    = "FAILED"
+	BlueprintRequestStatusCancelled = "CANCELLED"
+)
+
+// The deployment plans are computed during terraform plan, which has no configurable timeout
+const deploymentPlanTimeout = 5 * time.Minute
+
+// deploymentPlannedChangesSchema returns the schema to use for the planned_changes property
+func deploymentPlannedChangesSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Computed:    true,
+		Description: "The changes to the resources of the deployment planned by the plan, when simulate is enabled. They are empty once applied.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"change": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "The planned change of the resource. One of `CREATE`, `RECREATE`, `UPDATE`, `DELETE` or `ACTION`.",
+				},
+				"depends_on": {
+					Type:        schema.TypeList,
+					Computed:    true,
+					Description: "The names of the resources this resource depends on.",
+					Elem: &schema.Schema{
+						Type: schema.TypeString,
+					},
+				},
+				"resource_name": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "The name of the resource in the cloud template.",
+				},
+				"resource_type": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "The type of the resource.",
+				},
+			},
+		},
+	}
+}
+
+// resourceDeploymentCustomizeDiff plans the deployment with a plan only blueprint request when simulate is enabled,
+// so that the changes to the resources of the deployment are shown by terraform plan.
+func resourceDeploymentCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.Get("simulate").(bool) {
+		return nil
+	}
+
+	if d.Id() != "" && !d.HasChanges("blueprint_id", "blueprint_version", "blueprint_content", "catalog_item_id", "catalog_item_version", "inputs", "inputs_json") {
+		return nil
+	}
+
+	for _, key := range []string{"blueprint_id", "blueprint_version", "blueprint_content", "catalog_item_id", "catalog_item_version", "inputs", "inputs_json", "name", "project_id"} {
+		if deploymentPlanValueUnknown(d, key) {
+			log.Printf("[DEBUG] Unable to plan vra_deployment %s, the value of %s is not known yet", d.Get("name"), key)
+			return d.SetNewComputed("planned_changes")
+		}
+	}
+
+	apiClient := m.(*Client).apiClient
+
+	blueprintRequest, err := getDeploymentPlanRequest(d, apiClient)
+	if err != nil {
+		return err
+	}
+	if blueprintRequest == nil {
+		return d.SetNewComputed("planned_changes")
+	}
+
+	plannedChanges, err := planDeployment(ctx, apiClient, blueprintRequest)
+	if err != nil {
+		return fmt.Errorf("error planning vra_deployment %s: %s", d.Get("name"), err)
+	}
+	return d.SetNew("planned_changes", plannedChanges)
+}
+
+// deploymentPlanValueUnknown returns whether the configured value of the key is not known yet. The computed attributes
+// which are not configured are unknown in the diff of a new deployment too, so their raw configuration is checked.
+func deploymentPlanValueUnknown(d *schema.ResourceDiff, key string) bool {
+	if d.NewValueKnown(key) {
+		return false
+	}
+	switch key {
+	case "blueprint_id", "blueprint_version", "catalog_item_id", "catalog_item_version":
+		value, diags := d.GetRawConfigAt(cty.GetAttrPath(key))
+		return !diags.HasError() && !value.IsKnown()
+	default:
+		return true
+	}
+}
+
+// getDeploymentPlanRequest returns the plan only blueprint request of the deployment, or nil if the deployment is
+// requested from a catalog item which is not a cloud template and cannot be planned.
+func getDeploymentPlanRequest(d *schema.ResourceDiff, apiClient *client.API) (*models.BlueprintRequest, error) {
+	blueprintRequest := &models.BlueprintRequest{
+		DeploymentID:   d.Id(),
+		DeploymentName: d.Get("name").(string),
+		ProjectID:      d.Get("project_id").(string),
+		Inputs:         make(map[string]interface{}),
+		Plan:           true,
+		Reason:         "Planned deployment from vRA provider for Terraform.",
+	}
+
+	inputs, _ := getDeploymentInputs(d)
+
+	if v, ok := d.GetOk("catalog_item_id"); ok {
+		catalogItemID := v.(string)
+		catalogItemVersion := d.Get("catalog_item_version").(string)
+
+		getResp, err := apiClient.CatalogItems.GetCatalogItemUsingGET5(catalog_items.NewGetCatalogItemUsingGET5Params().WithID(strfmt.UUID(catalogItemID)))
+		if err != nil {
+			return nil, err
+		}
+		catalogItem := getResp.GetPayload()
+		if catalogItem.Type == nil || catalogItem.Type.ID != CatalogItemBlueprintTypeID {
+			log.Printf("[DEBUG] Unable to plan vra_deployment %s, catalog item %s is not a cloud template", d.Get("name"), catalogItemID)
+			return nil, nil
+		}
+
+		blueprintRequest.BlueprintID = strfmt.UUID(catalogItem.ExternalID)
+		blueprintRequest.BlueprintVersion = catalogItemVersion
+		if len(inputs) > 0 {
+			blueprintRequest.Inputs, err = getCatalogItemInputsByType(apiClient, catalogItemID, catalogItemVersion, inputs)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return blueprintRequest, nil
+	}
+
+	blueprintID := d.Get("blueprint_id").(string)
+	blueprintContent := d.Get("blueprint_content").(string)
+
+	// The blueprint_id of a deployment requested with blueprint_content is computed as inline-blueprint
+	if blueprintContent != "" {
+		blueprintRequest.Content = blueprintContent
+		blueprintRequest.Inputs = expandInputs(inputs)
+		return blueprintRequest, nil
+	}
+
+	if blueprintID == "" {
+		return nil, nil
+	}
+
+	blueprintVersion := d.Get("blueprint_version").(string)
+	blueprintRequest.BlueprintID = strfmt.UUID(blueprintID)
+	blueprintRequest.BlueprintVersion = blueprintVersion
+	if len(inputs) > 0 {
+		var err error
+		blueprintRequest.Inputs, err = getBlueprintInputsByType(apiClient, blueprintID, blueprintVersion, inputs)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return blueprintRequest, nil
+}
+
+// planDeployment submits the plan only blueprint request, waits for the plan and returns the planned changes.
+func planDeployment(ctx context.Context, apiClient *client.API, blueprintRequest *models.BlueprintRequest) ([]map[string]interface{}, error) {
+	log.Printf("[DEBUG] Plan deployment: %#v", blueprintRequest)
+	bpRequestCreated, bpRequestAccepted, err := apiClient.BlueprintRequests.CreateBlueprintRequestUsingPOST1(
+		blueprint_requests.NewCreateBlueprintRequestUsingPOST1Params().WithRequest(blueprintRequest))
+	if err != nil {
+		return nil, err
+	}
+
+	var bpRequest *models.BlueprintRequest
+	if bpRequestAccepted != nil {
+		bpRequest = bpRequestAccepted.GetPayload()
+	} else {
+		bpRequest = bpRequestCreated.GetPayload()
+	}
+	if bpRequest == nil || bpRequest.ID == "" {
+		return nil, fmt.Errorf("failed to request the plan of the deployment")
+	}
+
+	requestID := strfmt.UUID(bpRequest.ID)
+	defer func() {
+		_, err := apiClient.BlueprintRequests.DeleteBlueprintRequestUsingDELETE1(
+			blueprint_requests.NewDeleteBlueprintRequestUsingDELETE1Params().WithRequestID(requestID))
+		if err != nil {
+			log.Printf("[WARN] Unable to delete the plan request %s: %s", requestID, err)
+		}
+	}()
+
+	switch bpRequest.Status {
+	case BlueprintRequestStatusFinished:
+	case BlueprintRequestStatusFailed, BlueprintRequestStatusCancelled:
+		return nil, fmt.Errorf("plan request %s is %s: %s", requestID, bpRequest.Status, bpRequest.FailureMessage)
+	default:
+		stateChangeFunc := retry.StateChangeConf{
+			Delay:      pollDelay,
+			Pending:    []string{BlueprintRequestStatusCreated, BlueprintRequestStatusStarted},
+			Refresh:    blueprintRequestStatusRefreshFunc(*apiClient, requestID),
+			Target:     []string{BlueprintRequestStatusFinished},
+			Timeout:    deploymentPlanTimeout,
+			MinTimeout: pollMinTimeout,
+		}
+		if _, err := stateChangeFunc.WaitForStateContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	getResp, err := apiClient.BlueprintRequests.GetBlueprintResourcesPlanUsingGET1(
+		blueprint_requests.NewGetBlueprintResourcesPlanUsingGET1Params().WithRequestID(requestID))
+	if err != nil {
+		return nil, err
+	}
+	return flattenDeploymentPlannedChanges(getResp.GetPayload()), nil
+}
+
+func blueprintRequestStatusRefreshFunc(apiClient client.API, requestID strfmt.UUID) retry.StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		ret, err := apiClient.BlueprintRequests.GetBlueprintRequestUsingGET1(
+			blueprint_requests.NewGetBlueprintRequestUsingGET1Params().WithRequestID(requestID))
+		if err != nil {
+			return "", BlueprintRequestStatusFailed, err
+		}
+
+		status := ret.Payload.Status
+		switch status {
+		case BlueprintRequestStatusCreated, BlueprintRequestStatusStarted, BlueprintRequestStatusFinished:
+			return requestID.String(), status, nil
+		case BlueprintRequestStatusFailed, BlueprintRequestStatusCancelled:
+			return requestID.String(), status, fmt.Errorf("plan request %s is %s: %s", requestID, status, ret.Payload.FailureMessage)
+		default:
+			return requestID.String(), status, fmt.Errorf("blueprintRequestStatusRefreshFunc: unknown status %v", status)
+		}
+	}
+}
+
+// flattenDeploymentPlannedChanges returns the resources of the plan which are changed, in the order of the plan.
+func flattenDeploymentPlannedChanges(plan *models.BlueprintResourcesPlan) []map[string]interface{} {
+	plannedChanges := make([]map[string]interface{}, 0)
+	if plan == nil {
+		return plannedChanges
+	}
+
+	for _, resource := range plan.Resources {
+		// The resources which are only read are not changed
+		if resource.ResourceReason == "" || resource.ResourceReason == "READ" {
+			continue
+		}
+		plannedChanges = append(plannedChanges, map[string]interface{}{
+			"change":        resource.ResourceReason,
+			"depends_on":    resource.DependsOnResources,
+			"resource_name": resource.ResourceName,
+			"resource_type": resource.ResourceType,
+		})
+	}
+	return plannedChanges
+}
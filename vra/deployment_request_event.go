@@ -0,0 +1,200 @@
+// © Broadcom. All Rights Reserved.
+// The term “Broadcom” refers to Broadcom Inc. and/or its subsidiaries.
+// SPDX-License-Identifier: MPL-2.0
+
+package vra
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/vra-sdk-go/pkg/client"
+	"github.com/vmware/vra-sdk-go/pkg/client/deployments"
+	"github.com/vmware/vra-sdk-go/pkg/client/requests"
+	"github.com/vmware/vra-sdk-go/pkg/models"
+)
+
+// deploymentRequestEventsSchema returns the schema to use for the last_request_events property
+func deploymentRequestEventsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Computed:    true,
+		Description: "The events of the timeline of the last request on the deployment.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"details": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "Longer user-friendly details of the event.",
+				},
+				"id": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "Event identifier.",
+				},
+				"name": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "Short user-friendly label of the event.",
+				},
+				"resource_name": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "Name of the resource to which the event applies to, if any.",
+				},
+				"resource_type": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "Type of the resource to which the event applies to, if any.",
+				},
+				"timestamp": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "Time of the event.",
+				},
+				"user_event": {
+					Type:        schema.TypeBool,
+					Computed:    true,
+					Description: "Indicates whether the event represents a user input.",
+				},
+			},
+		},
+	}
+}
+
+func flattenDeploymentRequestEvents(events []*models.Event) []map[string]interface{} {
+	if len(events) == 0 {
+		return make([]map[string]interface{}, 0)
+	}
+
+	eventsList := make([]map[string]interface{}, 0, len(events))
+	for _, event := range events {
+		helper := make(map[string]interface{})
+		helper["details"] = event.Details
+		helper["id"] = event.ID.String()
+		helper["name"] = event.Name
+		helper["resource_name"] = event.ResourceName
+		helper["resource_type"] = event.ResourceType
+		helper["timestamp"] = ""
+		if event.Timestamp != nil {
+			helper["timestamp"] = event.Timestamp.String()
+		}
+		helper["user_event"] = event.UserEvent
+
+		eventsList = append(eventsList, helper)
+	}
+
+	return eventsList
+}
+
+// getDeploymentRequestEvents returns the events of the timeline of the deployment request.
+func getDeploymentRequestEvents(apiClient *client.API, requestID strfmt.UUID) ([]*models.Event, error) {
+	getResp, err := apiClient.Requests.GetRequestEventsUsingGET2(
+		requests.NewGetRequestEventsUsingGET2Params().
+			WithRequestID(requestID).
+			WithAPIVersion(withString(DeploymentsAPIVersion)))
+	if err != nil {
+		return nil, err
+	}
+	return getResp.GetPayload().Content, nil
+}
+
+// deploymentRequestFailureDiagnostics returns the diagnostics of a failed request of the deployment, detailed with the
+// events of the request and the errors of its failed resources, such as the failures of the extensibility actions and
+// workflows, so that the cause of the failure is known without opening the UI.
+func deploymentRequestFailureDiagnostics(apiClient *client.API, deploymentID string, summary string, err error) diag.Diagnostics {
+	diagnostic := diag.Diagnostic{
+		Severity: diag.Error,
+		Summary:  fmt.Sprintf("%s: %s", summary, err),
+	}
+
+	details, detailsErr := getDeploymentRequestFailureDetails(apiClient, deploymentID)
+	if detailsErr != nil {
+		log.Printf("[WARN] Unable to retrieve the events of the last request of deployment %s: %s", deploymentID, detailsErr)
+	}
+	diagnostic.Detail = details
+
+	return diag.Diagnostics{diagnostic}
+}
+
+// getDeploymentRequestFailureDetails returns the events of the last request of the deployment and the errors of its
+// failed resources.
+func getDeploymentRequestFailureDetails(apiClient *client.API, deploymentID string) (string, error) {
+	getResp, err := apiClient.Deployments.GetDeploymentByIDV3UsingGET(
+		deployments.NewGetDeploymentByIDV3UsingGETParams().
+			WithDeploymentID(strfmt.UUID(deploymentID)).
+			WithExpand([]string{"lastRequest"}).
+			WithAPIVersion(withString(DeploymentsAPIVersion)))
+	if err != nil {
+		return "", err
+	}
+
+	lastRequest := getResp.GetPayload().LastRequest
+	if lastRequest == nil {
+		return "", nil
+	}
+
+	var details strings.Builder
+
+	events, err := getDeploymentRequestEvents(apiClient, lastRequest.ID)
+	if err != nil {
+		return "", err
+	}
+	if len(events) > 0 {
+		fmt.Fprintf(&details, "Events of request %s:", lastRequest.ID)
+		for _, event := range events {
+			fields := make([]string, 0, 2)
+			if event.Timestamp != nil {
+				fields = append(fields, event.Timestamp.String())
+			}
+			if event.Name != nil {
+				fields = append(fields, *event.Name)
+			}
+			fmt.Fprintf(&details, "\n  %s", strings.Join(fields, " "))
+			if event.ResourceName != "" {
+				fmt.Fprintf(&details, " [%s (%s)]", event.ResourceName, event.ResourceType)
+			}
+			if event.Details != "" {
+				fmt.Fprintf(&details, ": %s", event.Details)
+			}
+		}
+	}
+
+	getResourcesResp, err := apiClient.Deployments.GetDeploymentResourcesUsingGET2(
+		deployments.NewGetDeploymentResourcesUsingGET2Params().
+			WithDeploymentID(strfmt.UUID(deploymentID)).
+			WithExpand([]string{"currentRequest"}).
+			WithAPIVersion(withString(DeploymentsAPIVersion)).
+			WithDollarTop(withInt32(DefaultDollarTop)))
+	if err != nil {
+		return details.String(), err
+	}
+
+	var failedResources []string
+	for _, resource := range getResourcesResp.GetPayload().Content {
+		if resource.CurrentRequest == nil || resource.CurrentRequest.Status != models.RequestStatusFAILED {
+			continue
+		}
+		name := resource.ID.String()
+		if resource.Name != nil {
+			name = *resource.Name
+		}
+		if resource.Type != nil {
+			name = fmt.Sprintf("%s (%s)", name, *resource.Type)
+		}
+		failedResources = append(failedResources, fmt.Sprintf("\n  %s: %s", name, resource.CurrentRequest.Details))
+	}
+	if len(failedResources) > 0 {
+		if details.Len() > 0 {
+			details.WriteString("\n\n")
+		}
+		details.WriteString("Failed resources:")
+		details.WriteString(strings.Join(failedResources, ""))
+	}
+
+	return details.String(), nil
+}
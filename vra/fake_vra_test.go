@@ -0,0 +1,1656 @@
+// © Broadcom. All Rights Reserved.
+// The term “Broadcom” refers to Broadcom Inc. and/or its subsidiaries.
+// SPDX-License-Identifier: MPL-2.0
+
+package vra
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	ctyjson "github.com/hashicorp/go-cty/cty/json"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/vmware/vra-sdk-go/pkg/models"
+)
+
+const (
+	fakeVRAOrgID = "fake-org-id"
+	fakeVRAUser  = "fake-user@example.com"
+)
+
+// fakeVRA is an in-process fake of the IaaS, Catalog, Deployment and Policy APIs, used to unit test the resources
+// without an appliance. The state is kept in memory and the asynchronous operations complete once they have been
+// polled pendingPolls times, so that the waiting logic of the resources is exercised too.
+type fakeVRA struct {
+	server *httptest.Server
+
+	mu sync.Mutex
+
+	// Number of times an asynchronous operation is reported in progress before it completes
+	pendingPolls int
+
+	// Application version exposed in /automation/config.json, which is not found when empty
+	applicationVersion string
+
+	// When set, the deployment requests fail with this message
+	deploymentFailure string
+
+	// When set, the deployment creation and day-2 action requests are pending the approval of these approvers
+	approvers []string
+
+	// When set, the approvals are rejected with this comment
+	approvalRejection string
+
+	// When set, the events of the deployment requests cannot be retrieved
+	requestEventsUnavailable bool
+
+	// When set, a request failing with this message is submitted on the deployment right after each day-2 action,
+	// as by another user
+	concurrentRequestFailure string
+
+	// Maximum number of items in a page of the lists of deployments and policies regardless of $top, unless 0
+	pageSize int
+
+	// Number of deletes of deployments which fail before the deletes succeed, unless the failures are ignored
+	deleteFailures int
+
+	// Day-2 actions available on the deployments, with the properties of their inputs schema
+	deploymentActions map[string]map[string]interface{}
+
+	// Day-2 actions available on the resources of the deployments by resource type, with the properties of their
+	// inputs schema
+	resourceActions map[string]map[string]map[string]interface{}
+
+	nextID          int
+	machines        map[string]*models.Machine
+	requestTrackers map[string]*models.RequestTracker
+	catalogItems    map[string]*fakeCatalogItem
+	blueprints      map[string]*fakeBlueprint
+	deployments     map[string]*models.Deployment
+	requests        map[string]*models.Request
+	policies        map[string]*models.Policy
+	projects        map[string]*models.IaaSProject
+
+	// Events of the deployment requests, by request id
+	requestEvents map[string][]*models.Event
+
+	// Approvals of the deployment requests, by request id
+	approvals map[string]*deploymentApproval
+
+	// Outputs of the deployments, by deployment id
+	deploymentOutputs map[string]map[string]interface{}
+
+	// Tags of the deployments in the key:value format, by deployment id
+	deploymentTags map[string][]string
+
+	// Plan only blueprint requests, with their plan
+	blueprintRequests map[string]*fakeBlueprintRequest
+
+	// Pending asynchronous operations, by request tracker or deployment id
+	operations map[string]*fakeOperation
+}
+
+type fakeCatalogItem struct {
+	item     *models.CatalogItem
+	versions map[string]interface{}
+}
+
+type fakeBlueprint struct {
+	name     string
+	versions map[string]interface{}
+	latest   interface{}
+
+	// Names and types of the resources of each version, the latest version being ""
+	resources map[string]map[string]string
+
+	// Outputs of the deployments of the blueprint
+	outputs map[string]interface{}
+}
+
+type fakeBlueprintRequest struct {
+	request *models.BlueprintRequest
+	plan    *models.BlueprintResourcesPlan
+}
+
+// fakeOperation is an asynchronous operation completed after it has been polled a number of times.
+type fakeOperation struct {
+	pollsLeft int
+	complete  func()
+}
+
+// poll counts a poll of the operation, and completes it once it has been reported in progress enough times.
+func (o *fakeOperation) poll() bool {
+	if o.pollsLeft > 0 {
+		o.pollsLeft--
+		return false
+	}
+	o.complete()
+	return true
+}
+
+func newFakeVRA(t *testing.T) *fakeVRA {
+	// The operations of the fake complete after a given number of polls, which need no wait between them
+	delay, minTimeout := pollDelay, pollMinTimeout
+	pollDelay, pollMinTimeout = time.Millisecond, time.Millisecond
+	t.Cleanup(func() {
+		pollDelay, pollMinTimeout = delay, minTimeout
+	})
+
+	f := &fakeVRA{
+		pendingPolls: 1,
+		deploymentActions: map[string]map[string]interface{}{
+			"Deployment.ChangeLease": {
+				"Lease Expiration Date": map[string]interface{}{"type": "string", "format": "date-time"},
+			},
+			"Deployment.ChangeOwner": {
+				"New Owner": map[string]interface{}{"type": "string"},
+			},
+			"Deployment.Delete": {
+				"ignoreDeleteFailures": map[string]interface{}{"type": "boolean"},
+			},
+			"Deployment.EditTags": {},
+			"Deployment.PowerOff": {},
+			"Deployment.PowerOn":  {},
+			"Deployment.Update": {
+				"catalogItemVersion": map[string]interface{}{"type": "string"},
+			},
+		},
+		resourceActions: map[string]map[string]map[string]interface{}{
+			"Cloud.vSphere.Machine": {
+				"Cloud.vSphere.Machine.PowerOff": {},
+				"Cloud.vSphere.Machine.PowerOn":  {},
+				"Cloud.vSphere.Machine.Resize": {
+					"cpuCount":      map[string]interface{}{"type": "integer"},
+					"totalMemoryMB": map[string]interface{}{"type": "integer"},
+				},
+			},
+		},
+		machines:        make(map[string]*models.Machine),
+		requestTrackers: make(map[string]*models.RequestTracker),
+		catalogItems:    make(map[string]*fakeCatalogItem),
+		blueprints:      make(map[string]*fakeBlueprint),
+		deployments:     make(map[string]*models.Deployment),
+		requests:        make(map[string]*models.Request),
+		policies:        make(map[string]*models.Policy),
+		projects:        make(map[string]*models.IaaSProject),
+		operations:      make(map[string]*fakeOperation),
+
+		requestEvents:     make(map[string][]*models.Event),
+		approvals:         make(map[string]*deploymentApproval),
+		deploymentOutputs: make(map[string]map[string]interface{}),
+		deploymentTags:    make(map[string][]string),
+		blueprintRequests: make(map[string]*fakeBlueprintRequest),
+	}
+
+	// The routes and their payloads follow the operations and the models of vra-sdk-go, which are generated from the
+	// swagger documents of the APIs of the server, except for the few noted below.
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /automation/config.json", f.getConfig)
+	mux.HandleFunc("GET /iaas/api/about", f.getIaaSAbout)
+
+	mux.HandleFunc("POST /iaas/api/machines", f.createMachine)
+	mux.HandleFunc("GET /iaas/api/machines/{id}", f.getMachine)
+	mux.HandleFunc("PATCH /iaas/api/machines/{id}", f.updateMachine)
+	mux.HandleFunc("DELETE /iaas/api/machines/{id}", f.deleteMachine)
+	mux.HandleFunc("GET /iaas/api/machines/{id}/disks", f.getMachineDisks)
+	mux.HandleFunc("GET /iaas/api/request-tracker/{id}", f.getRequestTracker)
+	mux.HandleFunc("GET /iaas/api/projects", f.getProjects)
+
+	mux.HandleFunc("GET /catalog/api/items/{id}", f.getCatalogItem)
+	mux.HandleFunc("GET /catalog/api/items/{id}/versions/{version}", f.getCatalogItemVersion)
+	mux.HandleFunc("POST /catalog/api/items/{id}/request", f.requestCatalogItem)
+
+	mux.HandleFunc("GET /blueprint/api/blueprints/{id}/inputs-schema", f.getBlueprintInputsSchema)
+	mux.HandleFunc("GET /blueprint/api/blueprints/{id}/versions/{version}/inputs-schema", f.getBlueprintInputsSchema)
+	mux.HandleFunc("POST /blueprint/api/blueprint-requests", f.createBlueprintRequest)
+	mux.HandleFunc("GET /blueprint/api/blueprint-requests/{id}", f.getBlueprintRequest)
+	mux.HandleFunc("DELETE /blueprint/api/blueprint-requests/{id}", f.deleteBlueprintRequest)
+	mux.HandleFunc("GET /blueprint/api/blueprint-requests/{id}/resources-plan", f.getBlueprintResourcesPlan)
+
+	mux.HandleFunc("GET /deployment/api/deployments", f.getDeployments)
+	// The deployment also has the top-level outputs of the Deployment API, which models.Deployment has not
+	mux.HandleFunc("GET /deployment/api/deployments/{id}", f.getDeployment)
+	mux.HandleFunc("PATCH /deployment/api/deployments/{id}", f.patchDeployment)
+	mux.HandleFunc("DELETE /deployment/api/deployments/{id}", f.deleteDeployment)
+	mux.HandleFunc("GET /deployment/api/deployments/{id}/{collection}", f.getDeploymentCollection)
+	mux.HandleFunc("GET /deployment/api/deployments/{id}/actions/{actionId}", f.getDeploymentAction)
+	mux.HandleFunc("GET /deployment/api/deployments/{id}/resources/{resourceId}/actions", f.getResourceActions)
+	mux.HandleFunc("GET /deployment/api/deployments/{id}/resources/{resourceId}/actions/{actionId}", f.getResourceAction)
+	mux.HandleFunc("POST /deployment/api/deployments/{id}/resources/{resourceId}/requests", f.submitResourceAction)
+	// The catalogItemVersion input of the Update action is not part of the swagger documents, as the inputs of the
+	// actions are described by the schema of each action
+	mux.HandleFunc("POST /deployment/api/deployments/{id}/requests", f.submitDeploymentAction)
+	mux.HandleFunc("GET /deployment/api/requests/{id}", f.getDeploymentRequest)
+	mux.HandleFunc("POST /deployment/api/requests/{id}", f.actionDeploymentRequest)
+	mux.HandleFunc("GET /deployment/api/requests/{id}/events", f.getDeploymentRequestEvents)
+
+	// The Approval Service API is not in vra-sdk-go, the payloads follow the subset of its approvals read by
+	// getDeploymentRequestApproval
+	mux.HandleFunc("GET /approval/api/approvals", f.getApprovals)
+
+	mux.HandleFunc("GET /policy/api/policies", f.getPolicies)
+	mux.HandleFunc("POST /policy/api/policies", f.createPolicy)
+	mux.HandleFunc("GET /policy/api/policies/{id}", f.getPolicy)
+	mux.HandleFunc("DELETE /policy/api/policies/{id}", f.deletePolicy)
+
+	f.server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/automation/config.json" && !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+			fakeVRAError(w, http.StatusUnauthorized, "missing bearer token")
+			return
+		}
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(f.server.Close)
+
+	return f
+}
+
+// client returns a provider client talking to the fake.
+func (f *fakeVRA) client(t *testing.T) *Client {
+	apiClient, err := getAPIClient(f.server.URL, "fake-token", TransportOptions{Insecure: true}, 30, RetryOptions{}, true)
+	if err != nil {
+		t.Fatalf("error creating the client of the fake vRA: %s", err)
+	}
+	return &Client{url: f.server.URL, transportOptions: TransportOptions{Insecure: true}, apiClient: apiClient}
+}
+
+// newFakeVRADeployment returns a fake which completes the operations at once, a client talking to it and the
+// vra_deployment resource.
+func newFakeVRADeployment(t *testing.T) (*fakeVRA, *Client, *schema.Resource) {
+	t.Helper()
+
+	fake := newFakeVRA(t)
+	fake.pendingPolls = 0
+	return fake, fake.client(t), resourceDeployment()
+}
+
+// newID returns a new unique id. Must be called with the lock held.
+func (f *fakeVRA) newID() string {
+	f.nextID++
+	return fmt.Sprintf("00000000-0000-4000-8000-%012d", f.nextID)
+}
+
+// startOperation registers an asynchronous operation. Must be called with the lock held.
+func (f *fakeVRA) startOperation(id string, complete func()) {
+	f.operations[id] = &fakeOperation{pollsLeft: f.pendingPolls, complete: complete}
+}
+
+// pollOperation polls the pending operation with the given id, if any. Must be called with the lock held.
+func (f *fakeVRA) pollOperation(id string) {
+	if o, ok := f.operations[id]; ok && o.poll() {
+		delete(f.operations, id)
+	}
+}
+
+// addCatalogItem adds a catalog item whose versions all have the given inputs schema properties, and returns its id.
+func (f *fakeVRA) addCatalogItem(name string, properties map[string]interface{}, versions ...string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := strfmt.UUID(f.newID())
+	inputsSchema := map[string]interface{}{"type": "object", "properties": properties}
+	item := &fakeCatalogItem{
+		item: &models.CatalogItem{
+			ID:     &id,
+			Name:   withString(name),
+			Schema: inputsSchema,
+		},
+		versions: make(map[string]interface{}),
+	}
+	for _, version := range versions {
+		item.versions[version] = inputsSchema
+	}
+	f.catalogItems[id.String()] = item
+	return id.String()
+}
+
+// addBlueprint adds a blueprint whose versions all have the given inputs schema properties, and returns its id.
+func (f *fakeVRA) addBlueprint(name string, properties map[string]interface{}, versions ...string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := f.newID()
+	inputsSchema := map[string]interface{}{"type": "object", "properties": properties}
+	blueprint := &fakeBlueprint{
+		name:      name,
+		versions:  make(map[string]interface{}),
+		latest:    inputsSchema,
+		resources: make(map[string]map[string]string),
+	}
+	for _, version := range versions {
+		blueprint.versions[version] = inputsSchema
+	}
+	f.blueprints[id] = blueprint
+	return id
+}
+
+// addProject adds a project with the given name.
+func (f *fakeVRA) addProject(name string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := f.newID()
+	f.projects[id] = &models.IaaSProject{ID: withString(id), Name: name}
+	return id
+}
+
+// setRequiredInputs sets the required inputs of the inputs schema of all the versions of the catalog item or blueprint.
+func (f *fakeVRA) setRequiredInputs(id string, required ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var inputsSchema interface{}
+	if item, ok := f.catalogItems[id]; ok {
+		inputsSchema = item.item.Schema
+	} else {
+		inputsSchema = f.blueprints[id].latest
+	}
+	inputsSchema.(map[string]interface{})["required"] = required
+}
+
+// setBlueprintResources sets the names and types of the resources of a version of the blueprint, "" being the latest.
+func (f *fakeVRA) setBlueprintResources(id, version string, resources map[string]string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.blueprints[id].resources[version] = resources
+}
+
+// setBlueprintOutputs sets the outputs of the deployments of the blueprint.
+func (f *fakeVRA) setBlueprintOutputs(id string, outputs map[string]interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.blueprints[id].outputs = outputs
+}
+
+// addDeployment adds a deployment created successfully, changed by update, with the tags in the key:value format.
+func (f *fakeVRA) addDeployment(name, projectID string, update func(deployment *models.Deployment), tags ...string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	deployment := f.newDeployment(name, projectID, map[string]interface{}{})
+	delete(f.operations, deployment.ID.String())
+	deployment.Status = models.DeploymentStatusCREATESUCCESSFUL
+	deployment.LastRequest.Status = models.RequestStatusSUCCESSFUL
+	if update != nil {
+		update(deployment)
+	}
+	f.deploymentTags[deployment.ID.String()] = tags
+	return deployment.ID.String()
+}
+
+// updateDeployment changes the deployment with the given id, as if it was changed outside of Terraform.
+func (f *fakeVRA) updateDeployment(id string, update func(deployment *models.Deployment)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	update(f.deployments[id])
+}
+
+// deployment returns a copy of the deployment with the given id, or nil if it does not exist.
+func (f *fakeVRA) deployment(id string) *models.Deployment {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	deployment, ok := f.deployments[id]
+	if !ok {
+		return nil
+	}
+	c := *deployment
+	return &c
+}
+
+func fakeVRAJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func fakeVRAError(w http.ResponseWriter, status int, message string) {
+	fakeVRAJSON(w, status, map[string]interface{}{"message": message, "statusCode": status})
+}
+
+// fakeVRAQueryList returns the values of the query parameter, either repeated or comma separated.
+func fakeVRAQueryList(r *http.Request, key string) []string {
+	values := make([]string, 0)
+	for _, value := range r.URL.Query()[key] {
+		values = append(values, strings.Split(value, ",")...)
+	}
+	return values
+}
+
+func fakeVRADecode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		fakeVRAError(w, http.StatusBadRequest, err.Error())
+		return false
+	}
+	return true
+}
+
+// getProjects serves the projects, filtered by name.
+func (f *fakeVRA) getProjects(w http.ResponseWriter, r *http.Request) {
+	name, filtered := strings.CutPrefix(r.URL.Query().Get("$filter"), "name eq ")
+	name = strings.ReplaceAll(strings.TrimSuffix(strings.TrimPrefix(name, "'"), "'"), "''", "'")
+
+	projects := make([]*models.IaaSProject, 0)
+	for _, id := range fakeVRASortedKeys(f.projects) {
+		if !filtered || f.projects[id].Name == name {
+			projects = append(projects, f.projects[id])
+		}
+	}
+	fakeVRAJSON(w, http.StatusOK, &models.ProjectResult{
+		Content:          projects,
+		NumberOfElements: int64(len(projects)),
+		TotalElements:    int64(len(projects)),
+	})
+}
+
+func (f *fakeVRA) getConfig(w http.ResponseWriter, _ *http.Request) {
+	if f.applicationVersion == "" {
+		fakeVRAError(w, http.StatusNotFound, "not found")
+		return
+	}
+	fakeVRAJSON(w, http.StatusOK, map[string]interface{}{
+		"applicationVersion": base64.StdEncoding.EncodeToString([]byte(f.applicationVersion)),
+	})
+}
+
+func (f *fakeVRA) getIaaSAbout(w http.ResponseWriter, _ *http.Request) {
+	fakeVRAJSON(w, http.StatusOK, &models.IaaSAbout{
+		LatestAPIVersion: withString(IaaSAPIVersion),
+		SupportedApis: []*models.APIDescription{
+			{APIVersion: withString("2019-01-15"), DocumentationLink: withString("/iaas/api/swagger")},
+			{APIVersion: withString(IaaSAPIVersion), DocumentationLink: withString("/iaas/api/swagger")},
+		},
+	})
+}
+
+// newRequestTracker returns a request tracker in progress, completed by the given function. Must be called with the lock held.
+func (f *fakeVRA) newRequestTracker(name string, complete func(tracker *models.RequestTracker)) *models.RequestTracker {
+	id := f.newID()
+	tracker := &models.RequestTracker{
+		ID:       withString(id),
+		Name:     name,
+		Progress: withInt32(0),
+		SelfLink: withString("/iaas/api/request-tracker/" + id),
+		Status:   withString(models.RequestTrackerStatusINPROGRESS),
+	}
+	f.requestTrackers[id] = tracker
+	f.startOperation(id, func() {
+		tracker.Progress = withInt32(100)
+		tracker.Status = withString(models.RequestTrackerStatusFINISHED)
+		complete(tracker)
+	})
+	return tracker
+}
+
+func (f *fakeVRA) getRequestTracker(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	f.pollOperation(id)
+
+	tracker, ok := f.requestTrackers[id]
+	if !ok {
+		fakeVRAError(w, http.StatusNotFound, "request tracker not found")
+		return
+	}
+	fakeVRAJSON(w, http.StatusOK, tracker)
+}
+
+func (f *fakeVRA) createMachine(w http.ResponseWriter, r *http.Request) {
+	var spec models.MachineSpecification
+	if !fakeVRADecode(w, r, &spec) {
+		return
+	}
+
+	id := f.newID()
+	machine := &models.Machine{
+		ID:               withString(id),
+		Name:             *spec.Name,
+		Description:      spec.Description,
+		DeploymentID:     spec.DeploymentID,
+		ProjectID:        *spec.ProjectID,
+		CustomProperties: spec.CustomProperties,
+		Tags:             spec.Tags,
+		ExternalRegionID: withString("fake-region"),
+		ExternalZoneID:   "fake-zone",
+		ExternalID:       "vm-" + id,
+		OrgID:            fakeVRAOrgID,
+		Owner:            fakeVRAUser,
+		PowerState:       withString(models.MachinePowerStateON),
+		CreatedAt:        time.Now().UTC().Format(time.RFC3339),
+		UpdatedAt:        time.Now().UTC().Format(time.RFC3339),
+		Links:            map[string]models.Href{"self": {Href: "/iaas/api/machines/" + id}},
+	}
+
+	tracker := f.newRequestTracker("Provisioning", func(tracker *models.RequestTracker) {
+		f.machines[id] = machine
+		tracker.Resources = []string{"/iaas/api/machines/" + id}
+	})
+	fakeVRAJSON(w, http.StatusAccepted, tracker)
+}
+
+func (f *fakeVRA) getMachine(w http.ResponseWriter, r *http.Request) {
+	machine, ok := f.machines[r.PathValue("id")]
+	if !ok {
+		fakeVRAError(w, http.StatusNotFound, "machine not found")
+		return
+	}
+	fakeVRAJSON(w, http.StatusOK, machine)
+}
+
+func (f *fakeVRA) updateMachine(w http.ResponseWriter, r *http.Request) {
+	machine, ok := f.machines[r.PathValue("id")]
+	if !ok {
+		fakeVRAError(w, http.StatusNotFound, "machine not found")
+		return
+	}
+
+	var spec models.UpdateMachineSpecification
+	if !fakeVRADecode(w, r, &spec) {
+		return
+	}
+
+	machine.Description = spec.Description
+	machine.Tags = spec.Tags
+	machine.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	fakeVRAJSON(w, http.StatusOK, machine)
+}
+
+func (f *fakeVRA) deleteMachine(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, ok := f.machines[id]; !ok {
+		fakeVRAError(w, http.StatusNotFound, "machine not found")
+		return
+	}
+
+	tracker := f.newRequestTracker("Remove", func(tracker *models.RequestTracker) {
+		delete(f.machines, id)
+		tracker.Resources = []string{"/iaas/api/machines/" + id}
+	})
+	fakeVRAJSON(w, http.StatusAccepted, tracker)
+}
+
+func (f *fakeVRA) getMachineDisks(w http.ResponseWriter, r *http.Request) {
+	if _, ok := f.machines[r.PathValue("id")]; !ok {
+		fakeVRAError(w, http.StatusNotFound, "machine not found")
+		return
+	}
+	fakeVRAJSON(w, http.StatusOK, &models.BlockDeviceResult{Content: []*models.BlockDevice{}})
+}
+
+func (f *fakeVRA) getCatalogItem(w http.ResponseWriter, r *http.Request) {
+	item, ok := f.catalogItems[r.PathValue("id")]
+	if !ok {
+		fakeVRAError(w, http.StatusNotFound, "catalog item not found")
+		return
+	}
+	fakeVRAJSON(w, http.StatusOK, item.item)
+}
+
+func (f *fakeVRA) getCatalogItemVersion(w http.ResponseWriter, r *http.Request) {
+	item, ok := f.catalogItems[r.PathValue("id")]
+	if !ok {
+		fakeVRAError(w, http.StatusNotFound, "catalog item not found")
+		return
+	}
+	version := r.PathValue("version")
+	inputsSchema, ok := item.versions[version]
+	if !ok {
+		fakeVRAError(w, http.StatusNotFound, "catalog item version not found")
+		return
+	}
+	fakeVRAJSON(w, http.StatusOK, &models.CatalogItemVersion{ID: version, Schema: inputsSchema})
+}
+
+func (f *fakeVRA) requestCatalogItem(w http.ResponseWriter, r *http.Request) {
+	catalogItemID := r.PathValue("id")
+	item, ok := f.catalogItems[catalogItemID]
+	if !ok {
+		fakeVRAError(w, http.StatusNotFound, "catalog item not found")
+		return
+	}
+
+	var request models.CatalogItemRequest
+	if !fakeVRADecode(w, r, &request) {
+		return
+	}
+
+	inputsSchema := item.item.Schema
+	if request.Version != "" {
+		if inputsSchema, ok = item.versions[request.Version]; !ok {
+			fakeVRAError(w, http.StatusBadRequest, "catalog item version not found")
+			return
+		}
+	}
+
+	deployment := f.newDeployment(request.DeploymentName, request.ProjectID, fakeVRAInputs(inputsSchema, request.Inputs))
+	deployment.CatalogItemID = catalogItemID
+	deployment.CatalogItemVersion = request.Version
+	deployment.LastRequest.CatalogItemID = catalogItemID + ":" + request.Version
+	fakeVRAJSON(w, http.StatusOK, []*models.CatalogItemRequestResponse{{
+		DeploymentID:   deployment.ID.String(),
+		DeploymentName: request.DeploymentName,
+	}})
+}
+
+func (f *fakeVRA) getBlueprintInputsSchema(w http.ResponseWriter, r *http.Request) {
+	blueprint, ok := f.blueprints[r.PathValue("id")]
+	if !ok {
+		fakeVRAError(w, http.StatusNotFound, "blueprint not found")
+		return
+	}
+
+	inputsSchema := blueprint.latest
+	if version := r.PathValue("version"); version != "" {
+		if inputsSchema, ok = blueprint.versions[version]; !ok {
+			fakeVRAError(w, http.StatusNotFound, "blueprint version not found")
+			return
+		}
+	}
+	fakeVRAJSON(w, http.StatusOK, inputsSchema)
+}
+
+func (f *fakeVRA) createBlueprintRequest(w http.ResponseWriter, r *http.Request) {
+	var request models.BlueprintRequest
+	if !fakeVRADecode(w, r, &request) {
+		return
+	}
+
+	var inputsSchema interface{}
+	var resources map[string]string
+	var outputs map[string]interface{}
+	if request.BlueprintID != "" {
+		blueprint, ok := f.blueprints[request.BlueprintID.String()]
+		if !ok {
+			fakeVRAError(w, http.StatusBadRequest, "blueprint not found")
+			return
+		}
+		inputsSchema = blueprint.latest
+		if request.BlueprintVersion != "" {
+			if inputsSchema, ok = blueprint.versions[request.BlueprintVersion]; !ok {
+				fakeVRAError(w, http.StatusBadRequest, "blueprint version not found")
+				return
+			}
+		}
+		resources = blueprint.resources[request.BlueprintVersion]
+		outputs = blueprint.outputs
+	}
+
+	var deployment *models.Deployment
+	if request.DeploymentID != "" {
+		var ok bool
+		if deployment, ok = f.deployments[request.DeploymentID]; !ok {
+			fakeVRAError(w, http.StatusBadRequest, "deployment not found")
+			return
+		}
+	}
+
+	request.ID = f.newID()
+	request.Status = BlueprintRequestStatusStarted
+
+	if request.Plan {
+		blueprintRequest := &fakeBlueprintRequest{request: &request, plan: fakeVRAPlan(deployment, resources)}
+		f.blueprintRequests[request.ID] = blueprintRequest
+
+		// The plans which are not polled are created finished
+		if f.pendingPolls == 0 {
+			request.Status = BlueprintRequestStatusFinished
+			fakeVRAJSON(w, http.StatusCreated, &request)
+			return
+		}
+		f.startOperation(request.ID, func() {
+			blueprintRequest.request.Status = BlueprintRequestStatusFinished
+		})
+		fakeVRAJSON(w, http.StatusAccepted, &request)
+		return
+	}
+
+	inputs := fakeVRAInputs(inputsSchema, request.Inputs)
+	if deployment == nil {
+		deployment = f.newDeployment(request.DeploymentName, request.ProjectID, inputs)
+	} else {
+		deployment.Inputs = inputs
+		deployment.Status = models.DeploymentStatusUPDATEINPROGRESS
+		deployment.LastRequest = f.newDeploymentRequest(deployment, "Update", inputs)
+		f.startOperation(deployment.ID.String(), func() {
+			deployment.Status = models.DeploymentStatusUPDATESUCCESSFUL
+			deployment.LastRequest.Status = models.RequestStatusSUCCESSFUL
+		})
+	}
+	deployment.BlueprintID = request.BlueprintID.String()
+	deployment.BlueprintVersion = request.BlueprintVersion
+	if request.BlueprintID != "" {
+		deployment.LastRequest.BlueprintID = request.BlueprintID.String() + ":" + request.BlueprintVersion
+	}
+	deployment.Description = request.Description
+	deployment.Resources = f.newDeploymentResources(resources)
+	f.deploymentOutputs[deployment.ID.String()] = outputs
+
+	request.DeploymentID = deployment.ID.String()
+	fakeVRAJSON(w, http.StatusAccepted, &request)
+}
+
+func (f *fakeVRA) getBlueprintRequest(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	f.pollOperation(id)
+
+	blueprintRequest, ok := f.blueprintRequests[id]
+	if !ok {
+		fakeVRAError(w, http.StatusNotFound, "blueprint request not found")
+		return
+	}
+	fakeVRAJSON(w, http.StatusOK, blueprintRequest.request)
+}
+
+func (f *fakeVRA) deleteBlueprintRequest(w http.ResponseWriter, r *http.Request) {
+	delete(f.blueprintRequests, r.PathValue("id"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *fakeVRA) getBlueprintResourcesPlan(w http.ResponseWriter, r *http.Request) {
+	blueprintRequest, ok := f.blueprintRequests[r.PathValue("id")]
+	if !ok {
+		fakeVRAError(w, http.StatusNotFound, "blueprint request not found")
+		return
+	}
+	fakeVRAJSON(w, http.StatusOK, blueprintRequest.plan)
+}
+
+// fakeVRAPlan returns the plan of a blueprint request with the given resources. The resources of the deployment are
+// created when they are new, updated when they are kept and deleted when they are removed.
+func fakeVRAPlan(deployment *models.Deployment, resources map[string]string) *models.BlueprintResourcesPlan {
+	existing := make(map[string]string)
+	if deployment != nil {
+		for _, resource := range deployment.Resources {
+			existing[*resource.Name] = *resource.Type
+		}
+	}
+
+	plan := &models.BlueprintResourcesPlan{}
+	for _, name := range fakeVRASortedKeys(resources) {
+		reason := "CREATE"
+		if _, ok := existing[name]; ok {
+			reason = "UPDATE"
+		}
+		plan.Resources = append(plan.Resources, &models.BlueprintPlanResource{ResourceName: name, ResourceType: resources[name], ResourceReason: reason})
+	}
+	for _, name := range fakeVRASortedKeys(existing) {
+		if _, ok := resources[name]; !ok {
+			plan.Resources = append(plan.Resources, &models.BlueprintPlanResource{ResourceName: name, ResourceType: existing[name], ResourceReason: "DELETE"})
+		}
+	}
+	return plan
+}
+
+func fakeVRASortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// newDeploymentResources returns the resources of a deployment with the given names and types. Must be called with
+// the lock held.
+func (f *fakeVRA) newDeploymentResources(resources map[string]string) []*models.DeploymentResource {
+	deploymentResources := make([]*models.DeploymentResource, 0, len(resources))
+	for _, name := range fakeVRASortedKeys(resources) {
+		properties := make(map[string]interface{})
+		if strings.HasSuffix(resources[name], "Machine") {
+			properties["powerState"] = "ON"
+		}
+		deploymentResources = append(deploymentResources, &models.DeploymentResource{
+			ID:         strfmt.UUID(f.newID()),
+			Name:       withString(name),
+			Type:       withString(resources[name]),
+			Properties: properties,
+			State:      "OK",
+			CreatedAt:  strfmt.DateTime(time.Now().UTC()),
+		})
+	}
+	return deploymentResources
+}
+
+// fakeVRAInputs returns the inputs of a request completed with the defaults of the inputs schema.
+func fakeVRAInputs(inputsSchema interface{}, requestInputs interface{}) map[string]interface{} {
+	inputs := make(map[string]interface{})
+	if s, ok := inputsSchema.(map[string]interface{}); ok {
+		properties, _ := s["properties"].(map[string]interface{})
+		for name, property := range properties {
+			if defaultValue, ok := property.(map[string]interface{})["default"]; ok {
+				inputs[name] = defaultValue
+			}
+		}
+	}
+	if m, ok := requestInputs.(map[string]interface{}); ok {
+		for name, value := range m {
+			inputs[name] = value
+		}
+	}
+	return inputs
+}
+
+// newDeployment adds a deployment being created, and starts its creation. Must be called with the lock held.
+func (f *fakeVRA) newDeployment(name, projectID string, inputs map[string]interface{}) *models.Deployment {
+	id := strfmt.UUID(f.newID())
+	now := strfmt.DateTime(time.Now().UTC())
+	deployment := &models.Deployment{
+		ID:            id,
+		Name:          withString(name),
+		ProjectID:     projectID,
+		OrgID:         fakeVRAOrgID,
+		OwnedBy:       fakeVRAUser,
+		CreatedBy:     fakeVRAUser,
+		CreatedAt:     now,
+		LastUpdatedBy: fakeVRAUser,
+		LastUpdatedAt: now,
+		Inputs:        inputs,
+		Status:        models.DeploymentStatusCREATEINPROGRESS,
+	}
+	deployment.LastRequest = f.newDeploymentRequest(deployment, "Create", inputs)
+	f.deployments[id.String()] = deployment
+
+	var approval *deploymentApproval
+	if len(f.approvers) > 0 {
+		approval = f.newApproval(deployment.LastRequest)
+	}
+
+	f.startOperation(id.String(), func() {
+		if approval != nil {
+			if f.approvalRejection != "" {
+				approval.Status = "REJECTED"
+				approval.ActionBy = fakeVRAUser
+				approval.Comment = f.approvalRejection
+				deployment.Status = models.DeploymentStatusCREATEFAILED
+				deployment.LastRequest.Status = models.RequestStatusAPPROVALREJECTED
+				return
+			}
+			approval.Status = "APPROVED"
+			approval.ActionBy = fakeVRAUser
+			deployment.LastRequest.ApprovedAt = strfmt.DateTime(time.Now().UTC())
+		}
+		if f.deploymentFailure != "" {
+			deployment.Status = models.DeploymentStatusCREATEFAILED
+			deployment.LastRequest.Status = models.RequestStatusFAILED
+			deployment.LastRequest.Details = f.deploymentFailure
+			for _, resource := range deployment.Resources {
+				resource.CurrentRequest = deployment.LastRequest
+				f.addRequestEvent(deployment.LastRequest, "Create failed", f.deploymentFailure, resource)
+			}
+			return
+		}
+		deployment.Status = models.DeploymentStatusCREATESUCCESSFUL
+		deployment.LastRequest.Status = models.RequestStatusSUCCESSFUL
+	})
+	return deployment
+}
+
+// newDeploymentRequest adds a request in progress on the deployment. Must be called with the lock held.
+func (f *fakeVRA) newDeploymentRequest(deployment *models.Deployment, actionID string, inputs interface{}) *models.Request {
+	now := strfmt.DateTime(time.Now().UTC())
+	request := &models.Request{
+		ID:             strfmt.UUID(f.newID()),
+		ActionID:       actionID,
+		Name:           withString(actionID),
+		DeploymentID:   deployment.ID,
+		Inputs:         inputs,
+		CreatedAt:      &now,
+		UpdatedAt:      now,
+		RequestedBy:    withString(fakeVRAUser),
+		Status:         models.RequestStatusINPROGRESS,
+		CompletedTasks: withInt32(0),
+		TotalTasks:     withInt32(1),
+	}
+	f.requests[request.ID.String()] = request
+	f.addRequestEvent(request, actionID+" requested", "", nil)
+	return request
+}
+
+// addRequestEvent adds an event to the timeline of the request, about the resource if not nil. Must be called with
+// the lock held.
+func (f *fakeVRA) addRequestEvent(request *models.Request, name, details string, resource *models.DeploymentResource) {
+	now := strfmt.DateTime(time.Now().UTC())
+	event := &models.Event{
+		ID:        strfmt.UUID(f.newID()),
+		Name:      withString(name),
+		Details:   details,
+		Timestamp: &now,
+	}
+	if resource != nil {
+		event.ResourceName = *resource.Name
+		event.ResourceType = *resource.Type
+	}
+	f.requestEvents[request.ID.String()] = append(f.requestEvents[request.ID.String()], event)
+}
+
+// newApproval makes the request pending the approval of the approvers. Must be called with the lock held.
+func (f *fakeVRA) newApproval(request *models.Request) *deploymentApproval {
+	approval := &deploymentApproval{
+		ID:        f.newID(),
+		RequestID: request.ID.String(),
+		Status:    "PENDING",
+		Approvers: f.approvers,
+	}
+	request.Status = models.RequestStatusAPPROVALPENDING
+	f.approvals[request.ID.String()] = approval
+	return approval
+}
+
+// getApprovals serves the approvals, filtered by request id.
+func (f *fakeVRA) getApprovals(w http.ResponseWriter, r *http.Request) {
+	requestID, ok := strings.CutPrefix(r.URL.Query().Get("$filter"), "requestId eq ")
+	if !ok {
+		fakeVRAError(w, http.StatusBadRequest, "unsupported filter")
+		return
+	}
+
+	page := &deploymentApprovalPage{Content: make([]*deploymentApproval, 0)}
+	if approval, ok := f.approvals[strings.Trim(requestID, "'")]; ok {
+		page.Content = append(page.Content, approval)
+	}
+	fakeVRAJSON(w, http.StatusOK, page)
+}
+
+// removeDeployment removes the deployment and its requests. Must be called with the lock held.
+func (f *fakeVRA) removeDeployment(id string) {
+	delete(f.deployments, id)
+	delete(f.deploymentOutputs, id)
+	delete(f.deploymentTags, id)
+	for requestID, request := range f.requests {
+		if request.DeploymentID.String() == id {
+			delete(f.requests, requestID)
+			delete(f.requestEvents, requestID)
+			delete(f.approvals, requestID)
+		}
+	}
+}
+
+func (f *fakeVRA) getDeploymentRequest(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	f.pollOperation(id)
+
+	request, ok := f.requests[id]
+	if !ok {
+		fakeVRAError(w, http.StatusNotFound, "request not found")
+		return
+	}
+	// The day-2 actions of the deployment are operations of the deployment
+	if request.DeploymentID != "" && request.Status != models.RequestStatusSUCCESSFUL {
+		f.pollOperation(request.DeploymentID.String())
+	}
+	fakeVRAJSON(w, http.StatusOK, request)
+}
+
+// getDeploymentRequests serves the requests of the deployment, the latest first.
+func (f *fakeVRA) getDeploymentRequests(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, ok := f.deployments[id]; !ok {
+		fakeVRAError(w, http.StatusNotFound, "deployment not found")
+		return
+	}
+
+	// The requests are served from the oldest, so that the last requests are on the last page
+	requests := make([]*models.Request, 0)
+	for _, requestID := range fakeVRASortedKeys(f.requests) {
+		if request := f.requests[requestID]; request.DeploymentID.String() == id {
+			requests = append(requests, request)
+		}
+	}
+
+	skip, top := f.page(r, len(requests))
+	content := requests[min(skip, len(requests)):min(skip+top, len(requests))]
+	fakeVRAJSON(w, http.StatusOK, &models.PageOfRequest{
+		Content:          content,
+		First:            skip == 0,
+		Last:             skip+top >= len(requests),
+		NumberOfElements: int32(len(content)),
+		TotalElements:    int64(len(requests)),
+	})
+}
+
+func (f *fakeVRA) getDeploymentRequestEvents(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, ok := f.requests[id]; !ok {
+		fakeVRAError(w, http.StatusNotFound, "request not found")
+		return
+	}
+	if f.requestEventsUnavailable {
+		fakeVRAError(w, http.StatusInternalServerError, "events unavailable")
+		return
+	}
+
+	events := f.requestEvents[id]
+	fakeVRAJSON(w, http.StatusOK, &models.PageOfEvent{
+		Content:          events,
+		NumberOfElements: int32(len(events)),
+		TotalElements:    int64(len(events)),
+		TotalPages:       1,
+	})
+}
+
+// actionDeploymentRequest cancels or dismisses the request.
+func (f *fakeVRA) actionDeploymentRequest(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	request, ok := f.requests[id]
+	if !ok {
+		fakeVRAError(w, http.StatusNotFound, "request not found")
+		return
+	}
+
+	switch r.URL.Query().Get("action") {
+	case "cancel":
+		if !request.Cancelable {
+			fakeVRAError(w, http.StatusBadRequest, "request cannot be canceled")
+			return
+		}
+		request.Status = models.RequestStatusABORTED
+		if deployment, ok := f.deployments[request.DeploymentID.String()]; ok {
+			delete(f.operations, deployment.ID.String())
+			if deployment.Status == models.DeploymentStatusCREATEINPROGRESS {
+				deployment.Status = models.DeploymentStatusCREATEFAILED
+			} else {
+				deployment.Status = models.DeploymentStatusUPDATEFAILED
+			}
+		}
+	case "dismiss":
+		request.Dismissed = true
+	default:
+		fakeVRAError(w, http.StatusBadRequest, "unsupported action")
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// getDeploymentCollection serves the deployment names, resources and actions, whose paths overlap.
+func (f *fakeVRA) getDeploymentCollection(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.PathValue("id") == "names":
+		f.checkDeploymentName(w, r.PathValue("collection"))
+	case r.PathValue("collection") == "resources":
+		f.getDeploymentResources(w, r)
+	case r.PathValue("collection") == "actions":
+		f.getDeploymentActions(w, r)
+	case r.PathValue("collection") == "requests":
+		f.getDeploymentRequests(w, r)
+	default:
+		fakeVRAError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (f *fakeVRA) checkDeploymentName(w http.ResponseWriter, name string) {
+	for _, deployment := range f.deployments {
+		if *deployment.Name == name {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+	}
+	fakeVRAError(w, http.StatusNotFound, "deployment name not found")
+}
+
+// getDeployments serves a page of the deployments sorted by name, filtered by name, project, owner, status, tags and
+// lease expiration date.
+func (f *fakeVRA) getDeployments(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	projects := fakeVRAQueryList(r, "projects")
+	owners := fakeVRAQueryList(r, "ownedBy")
+	statuses := fakeVRAQueryList(r, "status")
+	tags := fakeVRAQueryList(r, "tags")
+
+	var expiresAfter, expiresBefore time.Time
+	if expiresAt := fakeVRAQueryList(r, "expiresAt"); len(expiresAt) > 0 {
+		var errAfter, errBefore error
+		expiresAfter, errAfter = time.Parse(time.RFC3339, expiresAt[0])
+		expiresBefore, errBefore = time.Parse(time.RFC3339, expiresAt[len(expiresAt)-1])
+		if len(expiresAt) != 2 || errAfter != nil || errBefore != nil {
+			fakeVRAError(w, http.StatusBadRequest, "invalid expiresAt interval")
+			return
+		}
+	}
+
+	filtered := make([]*models.Deployment, 0, len(f.deployments))
+	for _, id := range fakeVRASortedKeys(f.deployments) {
+		deployment := f.deployments[id]
+		if (query.Has("name") && query.Get("name") != *deployment.Name) ||
+			(len(projects) > 0 && !slices.Contains(projects, deployment.ProjectID)) ||
+			(len(owners) > 0 && !slices.Contains(owners, deployment.OwnedBy)) ||
+			(len(statuses) > 0 && !slices.Contains(statuses, deployment.Status)) ||
+			slices.ContainsFunc(tags, func(tag string) bool { return !slices.Contains(f.deploymentTags[id], tag) }) {
+			continue
+		}
+		if !expiresAfter.IsZero() {
+			leaseExpireAt := time.Time(deployment.LeaseExpireAt)
+			if leaseExpireAt.IsZero() || leaseExpireAt.Before(expiresAfter) || leaseExpireAt.After(expiresBefore) {
+				continue
+			}
+		}
+		filtered = append(filtered, deployment)
+	}
+	sort.SliceStable(filtered, func(i, j int) bool { return *filtered[i].Name < *filtered[j].Name })
+
+	skip, top := f.page(r, len(filtered))
+	content := filtered[min(skip, len(filtered)):min(skip+top, len(filtered))]
+
+	fakeVRAJSON(w, http.StatusOK, &models.PageOfDeployment{
+		Content:          content,
+		First:            skip == 0,
+		Last:             skip+top >= len(filtered),
+		NumberOfElements: int32(len(content)),
+		TotalElements:    int64(len(filtered)),
+	})
+}
+
+func (f *fakeVRA) getDeployment(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	f.pollOperation(id)
+
+	deployment, ok := f.deployments[id]
+	if !ok {
+		fakeVRAError(w, http.StatusNotFound, "deployment not found")
+		return
+	}
+	fakeVRAJSON(w, http.StatusOK, struct {
+		*models.Deployment
+		Outputs map[string]interface{} `json:"outputs,omitempty"`
+	}{deployment, f.deploymentOutputs[id]})
+}
+
+func (f *fakeVRA) patchDeployment(w http.ResponseWriter, r *http.Request) {
+	deployment, ok := f.deployments[r.PathValue("id")]
+	if !ok {
+		fakeVRAError(w, http.StatusNotFound, "deployment not found")
+		return
+	}
+
+	var update models.DeploymentUpdate
+	if !fakeVRADecode(w, r, &update) {
+		return
+	}
+
+	if update.Name != "" {
+		deployment.Name = withString(update.Name)
+	}
+	deployment.Description = update.Description
+	deployment.LastUpdatedAt = strfmt.DateTime(time.Now().UTC())
+	fakeVRAJSON(w, http.StatusOK, deployment)
+}
+
+func (f *fakeVRA) deleteDeployment(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	deployment, ok := f.deployments[id]
+	if !ok {
+		fakeVRAError(w, http.StatusNotFound, "deployment not found")
+		return
+	}
+
+	deployment.Status = models.DeploymentStatusDELETEINPROGRESS
+	deployment.LastRequest = f.newDeploymentRequest(deployment, "Delete", nil)
+	f.startOperation(id, func() {
+		f.completeDeploymentDeletion(deployment, false)
+	})
+	fakeVRAJSON(w, http.StatusOK, deployment.LastRequest)
+}
+
+// completeDeploymentDeletion removes the deployment, unless the delete is one of the delete failures which are not
+// ignored. Must be called with the lock held.
+func (f *fakeVRA) completeDeploymentDeletion(deployment *models.Deployment, ignoreFailures bool) {
+	if f.deleteFailures > 0 && !ignoreFailures {
+		f.deleteFailures--
+		deployment.Status = models.DeploymentStatusDELETEFAILED
+		deployment.LastRequest.Status = models.RequestStatusFAILED
+		deployment.LastRequest.Details = "resource delete failed"
+		return
+	}
+	f.removeDeployment(deployment.ID.String())
+}
+
+func (f *fakeVRA) getDeploymentResources(w http.ResponseWriter, r *http.Request) {
+	deployment, ok := f.deployments[r.PathValue("id")]
+	if !ok {
+		fakeVRAError(w, http.StatusNotFound, "deployment not found")
+		return
+	}
+
+	names := fakeVRAQueryList(r, "names")
+	resourceTypes := fakeVRAQueryList(r, "resourceTypes")
+	resources := make([]*models.DeploymentResource, 0, len(deployment.Resources))
+	for _, resource := range deployment.Resources {
+		if (len(names) == 0 || slices.Contains(names, *resource.Name)) &&
+			(len(resourceTypes) == 0 || slices.Contains(resourceTypes, *resource.Type)) {
+			resources = append(resources, resource)
+		}
+	}
+	fakeVRAJSON(w, http.StatusOK, &models.PageOfDeploymentResource{
+		Content:          resources,
+		NumberOfElements: int32(len(resources)),
+		TotalElements:    int64(len(resources)),
+		TotalPages:       1,
+	})
+}
+
+func (f *fakeVRA) getDeploymentActions(w http.ResponseWriter, r *http.Request) {
+	deployment, ok := f.deployments[r.PathValue("id")]
+	if !ok {
+		fakeVRAError(w, http.StatusNotFound, "deployment not found")
+		return
+	}
+
+	actionIDs := make([]string, 0, len(f.deploymentActions))
+	for actionID := range f.deploymentActions {
+		actionIDs = append(actionIDs, actionID)
+	}
+	sort.Strings(actionIDs)
+
+	actions := make([]*models.ResourceAction, 0, len(actionIDs))
+	for _, actionID := range actionIDs {
+		actions = append(actions, f.deploymentAction(deployment, actionID))
+	}
+	fakeVRAJSON(w, http.StatusOK, actions)
+}
+
+func (f *fakeVRA) getDeploymentAction(w http.ResponseWriter, r *http.Request) {
+	deployment, ok := f.deployments[r.PathValue("id")]
+	if !ok {
+		fakeVRAError(w, http.StatusNotFound, "deployment not found")
+		return
+	}
+
+	actionID := r.PathValue("actionId")
+	if _, ok := f.deploymentActions[actionID]; !ok {
+		fakeVRAError(w, http.StatusNotFound, "action not found")
+		return
+	}
+	fakeVRAJSON(w, http.StatusOK, f.deploymentAction(deployment, actionID))
+}
+
+// deploymentAction returns the day-2 action of the deployment. Must be called with the lock held.
+func (f *fakeVRA) deploymentAction(deployment *models.Deployment, actionID string) *models.ResourceAction {
+	name := strings.TrimPrefix(actionID, "Deployment.")
+	return &models.ResourceAction{
+		ID:          actionID,
+		Name:        name,
+		DisplayName: name,
+		ProjectID:   deployment.ProjectID,
+		OrgID:       fakeVRAOrgID,
+		Schema:      map[string]interface{}{"type": "object", "properties": f.deploymentActions[actionID]},
+		Valid:       true,
+	}
+}
+
+func (f *fakeVRA) submitDeploymentAction(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	deployment, ok := f.deployments[id]
+	if !ok {
+		fakeVRAError(w, http.StatusNotFound, "deployment not found")
+		return
+	}
+
+	var actionRequest models.ResourceActionRequest
+	if !fakeVRADecode(w, r, &actionRequest) {
+		return
+	}
+
+	if _, ok := f.deploymentActions[actionRequest.ActionID]; !ok {
+		fakeVRAError(w, http.StatusBadRequest, fmt.Sprintf("action %s is not available on the deployment", actionRequest.ActionID))
+		return
+	}
+
+	inputs, _ := actionRequest.Inputs.(map[string]interface{})
+	request := f.newDeploymentRequest(deployment, actionRequest.ActionID, inputs)
+	deployment.LastRequest = request
+	switch actionRequest.ActionID {
+	case "Deployment.Delete":
+		deployment.Status = models.DeploymentStatusDELETEINPROGRESS
+	case "Deployment.Update":
+		deployment.Status = models.DeploymentStatusUPDATEINPROGRESS
+	}
+
+	var approval *deploymentApproval
+	if len(f.approvers) > 0 {
+		approval = f.newApproval(request)
+	}
+
+	if f.concurrentRequestFailure != "" {
+		concurrentRequest := f.newDeploymentRequest(deployment, actionRequest.ActionID, nil)
+		concurrentRequest.Status = models.RequestStatusFAILED
+		concurrentRequest.Details = f.concurrentRequestFailure
+		deployment.LastRequest = concurrentRequest
+	}
+
+	f.startOperation(id, func() {
+		if approval != nil {
+			approval.Status = "APPROVED"
+			approval.ActionBy = fakeVRAUser
+			request.ApprovedAt = strfmt.DateTime(time.Now().UTC())
+		}
+		switch actionRequest.ActionID {
+		case "Deployment.ChangeLease":
+			leaseExpireAt, _ := inputs["Lease Expiration Date"].(string)
+			deployment.LeaseExpireAt, _ = strfmt.ParseDateTime(leaseExpireAt)
+		case "Deployment.ChangeOwner":
+			deployment.OwnedBy, _ = inputs["New Owner"].(string)
+		case "Deployment.Delete":
+			ignoreFailures, _ := inputs["ignoreDeleteFailures"].(bool)
+			f.completeDeploymentDeletion(deployment, ignoreFailures)
+			if deployment.Status == models.DeploymentStatusDELETEFAILED {
+				return
+			}
+		case "Deployment.Update":
+			updated := fakeVRAInputs(nil, deployment.Inputs)
+			for name, value := range inputs {
+				if name == "catalogItemVersion" {
+					deployment.CatalogItemVersion, _ = value.(string)
+					continue
+				}
+				updated[name] = value
+			}
+			deployment.Inputs = updated
+			deployment.Status = models.DeploymentStatusUPDATESUCCESSFUL
+		}
+		request.Status = models.RequestStatusSUCCESSFUL
+		deployment.LastUpdatedAt = strfmt.DateTime(time.Now().UTC())
+	})
+	fakeVRAJSON(w, http.StatusOK, request)
+}
+
+// deploymentResource returns the resource of the deployment with the ids of the request. Must be called with the lock
+// held.
+func (f *fakeVRA) deploymentResource(w http.ResponseWriter, r *http.Request) (*models.Deployment, *models.DeploymentResource, bool) {
+	deployment, ok := f.deployments[r.PathValue("id")]
+	if !ok {
+		fakeVRAError(w, http.StatusNotFound, "deployment not found")
+		return nil, nil, false
+	}
+	for _, resource := range deployment.Resources {
+		if resource.ID.String() == r.PathValue("resourceId") {
+			return deployment, resource, true
+		}
+	}
+	fakeVRAError(w, http.StatusNotFound, "resource not found")
+	return nil, nil, false
+}
+
+// resourceAction returns the day-2 action of the resource. The power actions are only valid when they change the
+// power state of the resource. Must be called with the lock held.
+func (f *fakeVRA) resourceAction(deployment *models.Deployment, resource *models.DeploymentResource, actionID string) *models.ResourceAction {
+	name := actionID[strings.LastIndex(actionID, ".")+1:]
+	powerState, _ := resource.Properties.(map[string]interface{})["powerState"]
+	return &models.ResourceAction{
+		ID:          actionID,
+		Name:        name,
+		DisplayName: name,
+		ProjectID:   deployment.ProjectID,
+		OrgID:       fakeVRAOrgID,
+		Schema:      map[string]interface{}{"type": "object", "properties": f.resourceActions[*resource.Type][actionID]},
+		Valid:       !(name == "PowerOff" && powerState == "OFF") && !(name == "PowerOn" && powerState == "ON"),
+	}
+}
+
+func (f *fakeVRA) getResourceActions(w http.ResponseWriter, r *http.Request) {
+	deployment, resource, ok := f.deploymentResource(w, r)
+	if !ok {
+		return
+	}
+
+	actionIDs := make([]string, 0)
+	for actionID := range f.resourceActions[*resource.Type] {
+		actionIDs = append(actionIDs, actionID)
+	}
+	sort.Strings(actionIDs)
+
+	actions := make([]*models.ResourceAction, 0, len(actionIDs))
+	for _, actionID := range actionIDs {
+		actions = append(actions, f.resourceAction(deployment, resource, actionID))
+	}
+	fakeVRAJSON(w, http.StatusOK, actions)
+}
+
+func (f *fakeVRA) getResourceAction(w http.ResponseWriter, r *http.Request) {
+	deployment, resource, ok := f.deploymentResource(w, r)
+	if !ok {
+		return
+	}
+
+	actionID := r.PathValue("actionId")
+	if _, ok := f.resourceActions[*resource.Type][actionID]; !ok {
+		fakeVRAError(w, http.StatusNotFound, "action not found")
+		return
+	}
+	fakeVRAJSON(w, http.StatusOK, f.resourceAction(deployment, resource, actionID))
+}
+
+func (f *fakeVRA) submitResourceAction(w http.ResponseWriter, r *http.Request) {
+	deployment, resource, ok := f.deploymentResource(w, r)
+	if !ok {
+		return
+	}
+
+	var actionRequest models.ResourceActionRequest
+	if !fakeVRADecode(w, r, &actionRequest) {
+		return
+	}
+
+	if _, ok := f.resourceActions[*resource.Type][actionRequest.ActionID]; !ok {
+		fakeVRAError(w, http.StatusBadRequest, fmt.Sprintf("action %s is not available on the resource", actionRequest.ActionID))
+		return
+	}
+	if !f.resourceAction(deployment, resource, actionRequest.ActionID).Valid {
+		fakeVRAError(w, http.StatusBadRequest, fmt.Sprintf("action %s is not valid on the resource", actionRequest.ActionID))
+		return
+	}
+
+	inputs, _ := actionRequest.Inputs.(map[string]interface{})
+	request := f.newDeploymentRequest(deployment, actionRequest.ActionID, inputs)
+	request.ResourceIds = []strfmt.UUID{resource.ID}
+	deployment.LastRequest = request
+
+	f.startOperation(request.ID.String(), func() {
+		properties := resource.Properties.(map[string]interface{})
+		switch actionRequest.ActionID[strings.LastIndex(actionRequest.ActionID, ".")+1:] {
+		case "PowerOff":
+			properties["powerState"] = "OFF"
+		case "PowerOn":
+			properties["powerState"] = "ON"
+		default:
+			for name, value := range inputs {
+				properties[name] = value
+			}
+		}
+		request.Status = models.RequestStatusSUCCESSFUL
+	})
+	fakeVRAJSON(w, http.StatusOK, request)
+}
+
+func (f *fakeVRA) createPolicy(w http.ResponseWriter, r *http.Request) {
+	var policy models.Policy
+	if !fakeVRADecode(w, r, &policy) {
+		return
+	}
+
+	now := strfmt.DateTime(time.Now().UTC())
+	policy.LastUpdatedAt = now
+	policy.LastUpdatedBy = fakeVRAUser
+	policy.OrgID = fakeVRAOrgID
+
+	// Posting a policy with the id of an existing policy updates it
+	if existing, ok := f.policies[policy.ID.String()]; ok {
+		policy.CreatedAt = existing.CreatedAt
+		policy.CreatedBy = existing.CreatedBy
+		f.policies[policy.ID.String()] = &policy
+		fakeVRAJSON(w, http.StatusOK, &policy)
+		return
+	}
+
+	policy.ID = strfmt.UUID(f.newID())
+	policy.CreatedAt = now
+	policy.CreatedBy = fakeVRAUser
+	f.policies[policy.ID.String()] = &policy
+	fakeVRAJSON(w, http.StatusCreated, &policy)
+}
+
+func (f *fakeVRA) getPolicies(w http.ResponseWriter, r *http.Request) {
+	typeID := r.URL.Query().Get("typeId")
+
+	policies := make([]*models.Policy, 0)
+	for _, id := range fakeVRASortedKeys(f.policies) {
+		if typeID == "" || *f.policies[id].TypeID == typeID {
+			policies = append(policies, f.policies[id])
+		}
+	}
+
+	skip, top := f.page(r, len(policies))
+	content := policies[min(skip, len(policies)):min(skip+top, len(policies))]
+	fakeVRAJSON(w, http.StatusOK, &models.PageOfPolicy{
+		Content:          content,
+		First:            skip == 0,
+		Last:             skip+top >= len(policies),
+		NumberOfElements: int32(len(content)),
+		TotalElements:    int64(len(policies)),
+	})
+}
+
+// page returns the $skip and $top of the page of the list of items requested, limited to the page size.
+func (f *fakeVRA) page(r *http.Request, items int) (int, int) {
+	query := r.URL.Query()
+	skip, top := 0, items
+	if v := query.Get("$top"); v != "" {
+		fmt.Sscan(v, &top)
+	}
+	if v := query.Get("$skip"); v != "" {
+		fmt.Sscan(v, &skip)
+	}
+	if f.pageSize > 0 && top > f.pageSize {
+		top = f.pageSize
+	}
+	return skip, top
+}
+
+func (f *fakeVRA) getPolicy(w http.ResponseWriter, r *http.Request) {
+	policy, ok := f.policies[r.PathValue("id")]
+	if !ok {
+		fakeVRAError(w, http.StatusNotFound, "policy not found")
+		return
+	}
+	fakeVRAJSON(w, http.StatusOK, policy)
+}
+
+func (f *fakeVRA) deletePolicy(w http.ResponseWriter, r *http.Request) {
+	delete(f.policies, r.PathValue("id"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// testResourceApply plans the configuration against the state and applies the plan, as terraform apply does.
+func testResourceApply(t *testing.T, r *schema.Resource, state *terraform.InstanceState, raw map[string]interface{}, m interface{}) *terraform.InstanceState {
+	t.Helper()
+
+	ctx := context.Background()
+	config := terraform.NewResourceConfigRaw(raw)
+	if diags := r.Validate(config); diags.HasError() {
+		t.Fatalf("invalid configuration: %v", diags)
+	}
+
+	diff, err := r.Diff(ctx, testResourceDiffState(t, r, state, raw), config, m)
+	if err != nil {
+		t.Fatalf("error planning the configuration: %s", err)
+	}
+	if diff == nil {
+		return state
+	}
+
+	newState, diags := r.Apply(ctx, state, diff, m)
+	if diags.HasError() {
+		t.Fatalf("error applying the configuration: %v", diags)
+	}
+	return newState
+}
+
+// testResourceDiffState returns a copy of the state to plan the configuration against, with the raw configuration
+// set as terraform does for GetRawConfig.
+func testResourceDiffState(t *testing.T, r *schema.Resource, state *terraform.InstanceState, raw map[string]interface{}) *terraform.InstanceState {
+	t.Helper()
+
+	rawJSON, err := json.Marshal(raw)
+	if err != nil {
+		t.Fatalf("error marshalling the configuration: %s", err)
+	}
+	rawConfig, err := ctyjson.Unmarshal(rawJSON, r.CoreConfigSchema().ImpliedType())
+	if err != nil {
+		t.Fatalf("error converting the configuration: %s", err)
+	}
+
+	diffState := &terraform.InstanceState{}
+	if state != nil {
+		diffState = state.DeepCopy()
+	}
+	diffState.RawConfig = rawConfig
+	return diffState
+}
+
+// testResourceDestroy destroys the resource in the state.
+func testResourceDestroy(t *testing.T, r *schema.Resource, state *terraform.InstanceState, m interface{}) {
+	t.Helper()
+
+	if _, diags := r.Apply(context.Background(), state, &terraform.InstanceDiff{Destroy: true}, m); diags.HasError() {
+		t.Fatalf("error destroying the resource: %v", diags)
+	}
+}
+
+// testResourceRefresh refreshes the resource in the state. A nil state is returned when the resource is gone.
+func testResourceRefresh(t *testing.T, r *schema.Resource, state *terraform.InstanceState, m interface{}) *terraform.InstanceState {
+	t.Helper()
+
+	newState, diags := r.RefreshWithoutUpgrade(context.Background(), state, m)
+	if diags.HasError() {
+		t.Fatalf("error refreshing the resource: %v", diags)
+	}
+	return newState
+}
+
+// testResourceImport imports the resource with the given id, as terraform import does.
+func testResourceImport(t *testing.T, r *schema.Resource, id string, m interface{}) *terraform.InstanceState {
+	t.Helper()
+
+	d := r.Data(nil)
+	d.SetId(id)
+	imported, err := r.Importer.StateContext(context.Background(), d, m)
+	if err != nil {
+		t.Fatalf("error importing the resource %s: %s", id, err)
+	}
+	if len(imported) != 1 {
+		t.Fatalf("expected to import 1 resource, actual %d", len(imported))
+	}
+
+	state := testResourceRefresh(t, r, imported[0].State(), m)
+	if state == nil {
+		t.Fatalf("imported resource %s not found", id)
+	}
+	return state
+}
+
+// testCheckResourceAttrs checks the attributes of the state.
+func testCheckResourceAttrs(t *testing.T, state *terraform.InstanceState, expected map[string]string) {
+	t.Helper()
+
+	for key, value := range expected {
+		if actual := state.Attributes[key]; actual != value {
+			t.Errorf("attribute %s expected %q, actual %q", key, value, actual)
+		}
+	}
+}
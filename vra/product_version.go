@@ -0,0 +1,110 @@
+// © Broadcom. All Rights Reserved.
+// The term “Broadcom” refers to Broadcom Inc. and/or its subsidiaries.
+// SPDX-License-Identifier: MPL-2.0
+
+package vra
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// deploymentsAPIMinimumVersions are the first versions of the products serving the DeploymentsAPIVersion of the
+// Deployment API, which vRA introduced in 8.2.
+var deploymentsAPIMinimumVersions = map[string]string{ProductVRA: "8.2", ProductVCFA: "9.0"}
+
+// Resources and data sources which require a minimum version of the product, given the minimum version of each
+// product supporting them.
+var (
+	versionedResources = map[string]map[string]string{
+		"vra_deployment":                 deploymentsAPIMinimumVersions,
+		"vra_deployment_action":          deploymentsAPIMinimumVersions,
+		"vra_deployment_resource_action": deploymentsAPIMinimumVersions,
+	}
+
+	versionedDataSources = map[string]map[string]string{
+		"vra_deployment":           deploymentsAPIMinimumVersions,
+		"vra_deployment_resources": deploymentsAPIMinimumVersions,
+		"vra_deployments":          deploymentsAPIMinimumVersions,
+	}
+)
+
+// setMinimumVersions makes the resources and data sources fail with a "requires version" diagnostic, rather than with
+// the errors of an API missing from the appliance, when the version of the product does not support them.
+func setMinimumVersions(p *schema.Provider) {
+	for name, minimumVersions := range versionedResources {
+		guardResource(p.ResourcesMap[name], versionGuard(name, minimumVersions))
+	}
+	for name, minimumVersions := range versionedDataSources {
+		guardDataSource(p.DataSourcesMap[name], versionGuard(name, minimumVersions))
+	}
+}
+
+func versionGuard(name string, minimumVersions map[string]string) func(m interface{}) diag.Diagnostics {
+	return func(m interface{}) diag.Diagnostics {
+		return requireVersion(m, name, minimumVersions)
+	}
+}
+
+// requireVersion returns an error diagnostic when the appliance does not support a feature, given the minimum
+// version of each product supporting it. A product missing from minimumVersions does not support the feature at all.
+// No diagnostic is returned when the version of the appliance cannot be detected, so that the API can decide.
+func requireVersion(m interface{}, feature string, minimumVersions map[string]string) diag.Diagnostics {
+	c, ok := m.(*Client)
+	if !ok {
+		return nil
+	}
+
+	info, err := c.productInfo()
+	if err != nil {
+		log.Printf("[WARN] Unable to detect the product version to check the support of %s: %s", feature, err)
+		return nil
+	}
+
+	minimumVersion, ok := minimumVersions[info.product]
+	if !ok {
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("%s is not supported by %s", feature, info.product),
+			Detail:   fmt.Sprintf("%s requires %s.", feature, formatMinimumVersions(minimumVersions)),
+		}}
+	}
+
+	if info.version == "" {
+		return nil
+	}
+
+	currentVersion, err := version.NewVersion(info.version)
+	if err != nil {
+		log.Printf("[WARN] Unable to parse the product version %s: %s", info.version, err)
+		return nil
+	}
+	requiredVersion, err := version.NewVersion(minimumVersion)
+	if err != nil {
+		return diag.Errorf("error parsing the minimum version %s of %s: %s", minimumVersion, feature, err)
+	}
+
+	if currentVersion.LessThan(requiredVersion) {
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("%s requires %s %s or later", feature, info.product, minimumVersion),
+			Detail:   fmt.Sprintf("%s requires %s, but the version of %s is %s.", feature, formatMinimumVersions(minimumVersions), info.product, info.version),
+		}}
+	}
+	return nil
+}
+
+func formatMinimumVersions(minimumVersions map[string]string) string {
+	versions := make([]string, 0, len(minimumVersions))
+	for product, minimumVersion := range minimumVersions {
+		versions = append(versions, fmt.Sprintf("%s %s or later", product, minimumVersion))
+	}
+	sort.Strings(versions)
+	return strings.Join(versions, " or ")
+}
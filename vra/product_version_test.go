@@ -0,0 +1,54 @@
+// © Broadcom. All Rights Reserved.
+// The term “Broadcom” refers to Broadcom Inc. and/or its subsidiaries.
+// SPDX-License-Identifier: MPL-2.0
+
+package vra
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRequireVersion(t *testing.T) {
+	var tests = []struct {
+		product         string
+		version         string
+		minimumVersions map[string]string
+		err             bool
+	}{
+		{ProductVRA, "8.18.1.35409", map[string]string{ProductVRA: "8.12", ProductVCFA: "9.0"}, false},
+		{ProductVRA, "8.11.2.0", map[string]string{ProductVRA: "8.12", ProductVCFA: "9.0"}, true},
+		{ProductVRA, "", map[string]string{ProductVRA: "8.12"}, false},
+		{ProductVRA, "8.18.1.35409", map[string]string{ProductVCFA: "9.0"}, true},
+		{ProductVCFA, "9.0.0.0", map[string]string{ProductVCFA: "9.0.1"}, true},
+		{ProductVCFA, "9.0.1.0", map[string]string{ProductVCFA: "9.0.1"}, false},
+	}
+
+	for _, tt := range tests {
+		c := &Client{product: &productInfo{product: tt.product, version: tt.version}}
+		diags := requireVersion(c, "feature", tt.minimumVersions)
+		if diags.HasError() != tt.err {
+			t.Errorf("requireVersion for %s %s and %v expected error %t, actual %v", tt.product, tt.version, tt.minimumVersions, tt.err, diags)
+		}
+	}
+}
+
+func TestSetMinimumVersions(t *testing.T) {
+	p := Provider()
+	m := &Client{product: &productInfo{product: ProductVRA, version: "8.1.0.15986"}}
+
+	for name := range versionedDataSources {
+		diags := p.DataSourcesMap[name].ReadContext(context.Background(), nil, m)
+		if !diags.HasError() || !strings.Contains(diags[0].Summary, name+" requires vRA 8.2 or later") {
+			t.Errorf("data source %s expected a version error with vRA 8.1, actual %v", name, diags)
+		}
+	}
+
+	for name := range versionedResources {
+		diags := p.ResourcesMap[name].ReadContext(context.Background(), nil, m)
+		if !diags.HasError() || !strings.Contains(diags[0].Summary, name+" requires vRA 8.2 or later") {
+			t.Errorf("resource %s expected a version error with vRA 8.1, actual %v", name, diags)
+		}
+	}
+}
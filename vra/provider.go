@@ -7,6 +7,7 @@ package vra
 import (
 	"errors"
 	"os"
+	"time"
 
 	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -14,7 +15,7 @@ import (
 )
 
 func Provider() *schema.Provider {
-	return &schema.Provider{
+	p := &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"url": {
 				Type:        schema.TypeString,
@@ -26,22 +27,43 @@ func Provider() *schema.Provider {
 				Type:        schema.TypeString,
 				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("VCFA_ORGANIZATION", nil),
-				Description: "Organization name (required for VCF Automation).",
+				Description: "Organization name (required for VCF Automation). Use `system` for the provider organization.",
 			},
 			"refresh_token": {
 				Type:          schema.TypeString,
 				Optional:      true,
-				ConflictsWith: []string{"access_token"},
+				ConflictsWith: []string{"access_token", "username", "password"},
 				DefaultFunc:   schema.EnvDefaultFunc("VRA_REFRESH_TOKEN", nil),
 				Description:   "The refresh token for API operations.",
 			},
 			"access_token": {
 				Type:          schema.TypeString,
 				Optional:      true,
-				ConflictsWith: []string{"refresh_token"},
+				ConflictsWith: []string{"refresh_token", "username", "password"},
 				DefaultFunc:   schema.EnvDefaultFunc("VRA_ACCESS_TOKEN", nil),
 				Description:   "The access token for API operations.",
 			},
+			"username": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"refresh_token", "access_token"},
+				DefaultFunc:   schema.EnvDefaultFunc("VRA_USERNAME", nil),
+				Description:   "The username to log in with for API operations (vRA 8.x only).",
+			},
+			"password": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Sensitive:     true,
+				ConflictsWith: []string{"refresh_token", "access_token"},
+				DefaultFunc:   schema.EnvDefaultFunc("VRA_PASSWORD", nil),
+				Description:   "The password to log in with for API operations (vRA 8.x only).",
+			},
+			"domain": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("VRA_DOMAIN", nil),
+				Description: "The identity domain of the user to log in with.",
+			},
 			"insecure": {
 				Type:        schema.TypeBool,
 				DefaultFunc: schema.MultiEnvDefaultFunc([]string{"VRA_INSECURE", "VRA7_INSECURE"}, nil),
@@ -59,6 +81,55 @@ func Provider() *schema.Provider {
 					return diags
 				}),
 			},
+			"ca_file": {
+				Type:        schema.TypeString,
+				DefaultFunc: schema.EnvDefaultFunc("VRA_CA_FILE", nil),
+				Optional:    true,
+				Description: "Path to a PEM encoded CA bundle to validate TLS certificates with.",
+			},
+			"ca_pem": {
+				Type:        schema.TypeString,
+				DefaultFunc: schema.EnvDefaultFunc("VRA_CA_PEM", nil),
+				Optional:    true,
+				Description: "PEM encoded CA bundle to validate TLS certificates with.",
+			},
+			"client_cert_file": {
+				Type:        schema.TypeString,
+				DefaultFunc: schema.EnvDefaultFunc("VRA_CLIENT_CERT_FILE", nil),
+				Optional:    true,
+				Description: "Path to a PEM encoded client certificate for mutual TLS authentication.",
+			},
+			"client_key_file": {
+				Type:        schema.TypeString,
+				DefaultFunc: schema.EnvDefaultFunc("VRA_CLIENT_KEY_FILE", nil),
+				Optional:    true,
+				Description: "Path to the PEM encoded private key of the client certificate.",
+			},
+			"proxy_url": {
+				Type:        schema.TypeString,
+				DefaultFunc: schema.EnvDefaultFunc("VRA_PROXY_URL", nil),
+				Optional:    true,
+				Description: "The URL of the proxy for API operations. Overrides the HTTP_PROXY and HTTPS_PROXY environment variables.",
+			},
+			"proxy_username": {
+				Type:        schema.TypeString,
+				DefaultFunc: schema.EnvDefaultFunc("VRA_PROXY_USERNAME", nil),
+				Optional:    true,
+				Description: "The username to authenticate with the proxy.",
+			},
+			"proxy_password": {
+				Type:        schema.TypeString,
+				DefaultFunc: schema.EnvDefaultFunc("VRA_PROXY_PASSWORD", nil),
+				Optional:    true,
+				Sensitive:   true,
+				Description: "The password to authenticate with the proxy.",
+			},
+			"no_proxy": {
+				Type:        schema.TypeString,
+				DefaultFunc: schema.EnvDefaultFunc("VRA_NO_PROXY", nil),
+				Optional:    true,
+				Description: "Comma-separated list of hosts that bypass the proxy set with proxy_url.",
+			},
 			"reauthorize_timeout": {
 				Type:        schema.TypeString,
 				DefaultFunc: schema.MultiEnvDefaultFunc([]string{"VRA_REAUTHORIZE_TIMEOUT", "VRA7_REAUTHORIZE_TIMEOUT"}, nil),
@@ -82,9 +153,46 @@ func Provider() *schema.Provider {
 				Optional:    true,
 				Description: "Specify timeout in seconds for API operations.",
 			},
+			"max_retries": {
+				Type:        schema.TypeInt,
+				DefaultFunc: schema.EnvDefaultFunc("VRA_MAX_RETRIES", 3),
+				Optional:    true,
+				Description: "Specify the maximum number of retries for API operations failing with a transient error (429, 502, 503, 504).",
+			},
+			"retry_min_wait": {
+				Type:        schema.TypeInt,
+				DefaultFunc: schema.EnvDefaultFunc("VRA_RETRY_MIN_WAIT", 1),
+				Optional:    true,
+				Description: "Specify the minimum time in seconds to wait between retries of API operations.",
+			},
+			"retry_max_wait": {
+				Type:        schema.TypeInt,
+				DefaultFunc: schema.EnvDefaultFunc("VRA_RETRY_MAX_WAIT", 30),
+				Optional:    true,
+				Description: "Specify the maximum time in seconds to wait between retries of API operations.",
+			},
+			"retry_non_idempotent": {
+				Type:        schema.TypeBool,
+				DefaultFunc: schema.EnvDefaultFunc("VRA_RETRY_NON_IDEMPOTENT", false),
+				Optional:    true,
+				Description: "Specify whether to also retry non-idempotent (POST, PATCH) API operations.",
+			},
+			"log_http_body": {
+				Type:        schema.TypeBool,
+				DefaultFunc: schema.EnvDefaultFunc("VRA_LOG_HTTP_BODY", true),
+				Optional:    true,
+				Description: "Specify whether to include the request and response bodies of API operations in the debug logs.",
+			},
+			"token_cache_dir": {
+				Type:        schema.TypeString,
+				DefaultFunc: schema.EnvDefaultFunc("VRA_TOKEN_CACHE_DIR", nil),
+				Optional:    true,
+				Description: "Directory in which to cache access tokens across provider runs.",
+			},
 		},
 
 		DataSourcesMap: map[string]*schema.Resource{
+			"vra_about":                         dataSourceAbout(),
 			"vra_block_device":                  dataSourceBlockDevice(),
 			"vra_block_device_snapshots":        dataSourceBlockDeviceSnapshots(),
 			"vra_blueprint":                     dataSourceBlueprint(),
@@ -104,6 +212,8 @@ func Provider() *schema.Provider {
 			"vra_content_source":                dataSourceContentSource(),
 			"vra_data_collector":                dataSourceDataCollector(),
 			"vra_deployment":                    dataSourceDeployment(),
+			"vra_deployment_resources":          dataSourceDeploymentResources(),
+			"vra_deployments":                   dataSourceDeployments(),
 			"vra_fabric_compute":                dataSourceFabricCompute(),
 			"vra_fabric_datastore_vsphere":      dataSourceFabricDatastoreVsphere(),
 			"vra_fabric_network":                dataSourceFabricNetwork(),
@@ -156,6 +266,8 @@ func Provider() *schema.Provider {
 			"vra_content_sharing_policy":     resourceContentSharingPolicy(),
 			"vra_content_source":             resourceContentSource(),
 			"vra_deployment":                 resourceDeployment(),
+			"vra_deployment_action":          resourceDeploymentAction(),
+			"vra_deployment_resource_action": resourceDeploymentResourceAction(),
 			"vra_fabric_compute":             resourceFabricCompute(),
 			"vra_fabric_datastore_vsphere":   resourceFabricDatastoreVsphere(),
 			"vra_fabric_network_vsphere":     resourceFabricNetworkVsphere(),
@@ -181,6 +293,10 @@ func Provider() *schema.Provider {
 
 		ConfigureFunc: configureProvider,
 	}
+
+	setTenantOnly(p)
+	setMinimumVersions(p)
+	return p
 }
 
 func configureProvider(d *schema.ResourceData) (interface{}, error) {
@@ -188,8 +304,12 @@ func configureProvider(d *schema.ResourceData) (interface{}, error) {
 	organization := ""
 	refreshToken := ""
 	accessToken := ""
+	username := ""
+	password := ""
+	domain := ""
 	reauth := "0"
 	apiTimeout := 0
+	tokenCacheDir := ""
 
 	if v, ok := d.GetOk("organization"); ok {
 		organization = v.(string)
@@ -203,7 +323,57 @@ func configureProvider(d *schema.ResourceData) (interface{}, error) {
 		accessToken = v.(string)
 	}
 
-	insecure := d.Get("insecure").(bool)
+	if v, ok := d.GetOk("username"); ok {
+		username = v.(string)
+	}
+
+	if v, ok := d.GetOk("password"); ok {
+		password = v.(string)
+	}
+
+	if v, ok := d.GetOk("domain"); ok {
+		domain = v.(string)
+	}
+
+	transportOptions := TransportOptions{
+		Insecure: d.Get("insecure").(bool),
+	}
+
+	if v, ok := d.GetOk("ca_file"); ok {
+		transportOptions.CAFile = v.(string)
+	}
+
+	if v, ok := d.GetOk("ca_pem"); ok {
+		transportOptions.CAPEM = v.(string)
+	}
+
+	if v, ok := d.GetOk("client_cert_file"); ok {
+		transportOptions.ClientCertFile = v.(string)
+	}
+
+	if v, ok := d.GetOk("client_key_file"); ok {
+		transportOptions.ClientKeyFile = v.(string)
+	}
+
+	if v, ok := d.GetOk("proxy_url"); ok {
+		transportOptions.ProxyURL = v.(string)
+	}
+
+	if v, ok := d.GetOk("proxy_username"); ok {
+		transportOptions.ProxyUsername = v.(string)
+	}
+
+	if v, ok := d.GetOk("proxy_password"); ok {
+		transportOptions.ProxyPassword = v.(string)
+	}
+
+	if v, ok := d.GetOk("no_proxy"); ok {
+		transportOptions.NoProxy = v.(string)
+	}
+
+	if (transportOptions.ClientCertFile == "") != (transportOptions.ClientKeyFile == "") {
+		return nil, errors.New("client_cert_file and client_key_file must be specified together")
+	}
 
 	if v, ok := d.GetOk("reauthorize_timeout"); ok {
 		reauth = v.(string)
@@ -213,13 +383,50 @@ func configureProvider(d *schema.ResourceData) (interface{}, error) {
 		apiTimeout = v.(int)
 	}
 
+	if v, ok := d.GetOk("token_cache_dir"); ok {
+		tokenCacheDir = v.(string)
+	}
+
+	retryOptions := RetryOptions{
+		MaxRetries:         d.Get("max_retries").(int),
+		MinWait:            time.Duration(d.Get("retry_min_wait").(int)) * time.Second,
+		MaxWait:            time.Duration(d.Get("retry_max_wait").(int)) * time.Second,
+		RetryNonIdempotent: d.Get("retry_non_idempotent").(bool),
+	}
+
+	logHTTPBody := d.Get("log_http_body").(bool)
+
+	if retryOptions.MinWait > retryOptions.MaxWait {
+		return nil, errors.New("retry_min_wait must be less than or equal to retry_max_wait")
+	}
+
+	if username != "" || password != "" {
+		if accessToken != "" || refreshToken != "" {
+			return nil, errors.New("username and password cannot be used together with refresh_token or access_token")
+		}
+		if username == "" || password == "" {
+			return nil, errors.New("username and password are both required")
+		}
+		return NewClientFromCredentials(url, username, password, domain, transportOptions, reauth, apiTimeout, retryOptions, logHTTPBody)
+	}
+
 	if accessToken == "" && refreshToken == "" {
-		return nil, errors.New("refresh_token or access_token required")
+		return nil, errors.New("refresh_token, access_token or username and password required")
 	}
 
+	var client interface{}
+	var err error
 	if accessToken != "" {
-		return NewClientFromAccessToken(url, accessToken, insecure, apiTimeout)
+		client, err = NewClientFromAccessToken(url, organization, accessToken, transportOptions, apiTimeout, retryOptions, logHTTPBody)
+	} else {
+		client, err = NewClientFromRefreshToken(url, organization, refreshToken, transportOptions, reauth, apiTimeout, retryOptions, logHTTPBody, tokenCacheDir)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return NewClientFromRefreshToken(url, organization, refreshToken, insecure, reauth, apiTimeout)
+	if err := checkProviderOrganization(client.(*Client)); err != nil {
+		return nil, err
+	}
+	return client, nil
 }
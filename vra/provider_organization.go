@@ -0,0 +1,182 @@
+// © Broadcom. All Rights Reserved.
+// The term “Broadcom” refers to Broadcom Inc. and/or its subsidiaries.
+// SPDX-License-Identifier: MPL-2.0
+
+package vra
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// ProviderOrganization is the name of the provider organization of VCF Automation.
+// When the provider is configured with it, the access token is generated from the provider endpoint instead of the
+// endpoint of a tenant organization. The API requests are not routed to provider-scoped APIs, which vra-sdk-go does
+// not describe.
+const ProviderOrganization = "system"
+
+// Resources and data sources which only exist within a tenant organization, such as the projects and everything
+// consumed through them. They cannot be managed with the provider organization.
+var (
+	tenantOnlyResources = []string{
+		"vra_block_device",
+		"vra_block_device_snapshot",
+		"vra_blueprint",
+		"vra_blueprint_version",
+		"vra_catalog_item_entitlement",
+		"vra_catalog_item_vm_image",
+		"vra_catalog_item_vro_workflow",
+		"vra_catalog_source_blueprint",
+		"vra_catalog_source_entitlement",
+		"vra_content_sharing_policy",
+		"vra_content_source",
+		"vra_deployment",
+		"vra_deployment_action",
+		"vra_deployment_resource_action",
+		"vra_load_balancer",
+		"vra_machine",
+		"vra_network",
+		"vra_policy_approval",
+		"vra_policy_day2_action",
+		"vra_policy_iaas_resource",
+		"vra_policy_lease",
+		"vra_project",
+	}
+
+	tenantOnlyDataSources = []string{
+		"vra_block_device",
+		"vra_block_device_snapshots",
+		"vra_blueprint",
+		"vra_blueprint_version",
+		"vra_catalog_item",
+		"vra_catalog_item_entitlement",
+		"vra_catalog_source_blueprint",
+		"vra_catalog_source_entitlement",
+		"vra_content_sharing_policy",
+		"vra_content_source",
+		"vra_deployment",
+		"vra_deployment_resources",
+		"vra_deployments",
+		"vra_load_balancer",
+		"vra_machine",
+		"vra_network",
+		"vra_policy_approval",
+		"vra_policy_day2_action",
+		"vra_policy_iaas_resource",
+		"vra_policy_lease",
+		"vra_project",
+		"vra_security_group",
+	}
+)
+
+func isProviderOrganization(organization string) bool {
+	return strings.EqualFold(organization, ProviderOrganization)
+}
+
+// isProviderOrganization returns whether the client is scoped to the provider organization of VCF Automation.
+func (c *Client) isProviderOrganization() bool {
+	return isProviderOrganization(c.organization)
+}
+
+// checkProviderOrganization returns an error when the client is scoped to the provider organization, but the
+// appliance does not support it. Only VCF Automation has a provider organization.
+func checkProviderOrganization(c *Client) error {
+	if !c.isProviderOrganization() {
+		return nil
+	}
+
+	feature := fmt.Sprintf("The provider organization %q", ProviderOrganization)
+	if diags := requireVersion(c, feature, map[string]string{ProductVCFA: "9.0"}); diags.HasError() {
+		return fmt.Errorf("%s: %s", diags[0].Summary, diags[0].Detail)
+	}
+	return nil
+}
+
+// setTenantOnly marks the resources and data sources which only exist within a tenant organization.
+func setTenantOnly(p *schema.Provider) {
+	for _, name := range tenantOnlyResources {
+		tenantOnlyResource(name, p.ResourcesMap[name])
+	}
+	for _, name := range tenantOnlyDataSources {
+		tenantOnlyDataSource(name, p.DataSourcesMap[name])
+	}
+}
+
+// tenantOnlyDiagnostics returns an error when the client is scoped to the provider organization.
+func tenantOnlyDiagnostics(name string, m interface{}) diag.Diagnostics {
+	if c, ok := m.(*Client); !ok || !c.isProviderOrganization() {
+		return nil
+	}
+	return diag.Diagnostics{{
+		Severity: diag.Error,
+		Summary:  fmt.Sprintf("%s is not supported with the provider organization", name),
+		Detail: fmt.Sprintf("%s only exists within a tenant organization of VCF Automation, but the provider is configured with the provider organization %q. "+
+			"Configure the provider with the name of a tenant organization in organization to manage it.", name, ProviderOrganization),
+	}}
+}
+
+// tenantOnlyResource makes the operations of the resource fail when the client is scoped to the provider organization.
+func tenantOnlyResource(name string, r *schema.Resource) {
+	guardResource(r, func(m interface{}) diag.Diagnostics {
+		return tenantOnlyDiagnostics(name, m)
+	})
+}
+
+// tenantOnlyDataSource makes the data source fail when the client is scoped to the provider organization.
+func tenantOnlyDataSource(name string, r *schema.Resource) {
+	guardDataSource(r, func(m interface{}) diag.Diagnostics {
+		return tenantOnlyDiagnostics(name, m)
+	})
+}
+
+// guardResource makes the operations of the resource fail with the error diagnostics of the guard, if any.
+func guardResource(r *schema.Resource, guard func(m interface{}) diag.Diagnostics) {
+	create, read, update, del := r.CreateContext, r.ReadContext, r.UpdateContext, r.DeleteContext
+
+	r.CreateContext = func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+		if diags := guard(m); diags.HasError() {
+			return diags
+		}
+		return create(ctx, d, m)
+	}
+	r.ReadContext = func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+		if diags := guard(m); diags.HasError() {
+			return diags
+		}
+		return read(ctx, d, m)
+	}
+	if update != nil {
+		r.UpdateContext = func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+			if diags := guard(m); diags.HasError() {
+				return diags
+			}
+			return update(ctx, d, m)
+		}
+	}
+	r.DeleteContext = func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+		if diags := guard(m); diags.HasError() {
+			return diags
+		}
+		return del(ctx, d, m)
+	}
+}
+
+// guardDataSource makes the data source fail with the error diagnostics of the guard, if any.
+func guardDataSource(r *schema.Resource, guard func(m interface{}) diag.Diagnostics) {
+	read, readContext := r.Read, r.ReadContext
+
+	r.Read = nil
+	r.ReadContext = func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+		if diags := guard(m); diags.HasError() {
+			return diags
+		}
+		if readContext != nil {
+			return readContext(ctx, d, m)
+		}
+		return diag.FromErr(read(d, m))
+	}
+}
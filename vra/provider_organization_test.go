@@ -0,0 +1,88 @@
+// © Broadcom. All Rights Reserved.
+// The term “Broadcom” refers to Broadcom Inc. and/or its subsidiaries.
+// SPDX-License-Identifier: MPL-2.0
+
+package vra
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestTenantOnlyResource(t *testing.T) {
+	var tests = []struct {
+		organization string
+		err          bool
+	}{
+		{"", false},
+		{"acme", false},
+		{"system", true},
+		{"SYSTEM", true},
+	}
+
+	for _, tt := range tests {
+		called := false
+		r := &schema.Resource{
+			CreateContext: func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+				called = true
+				return nil
+			},
+			ReadContext:   schema.NoopContext,
+			DeleteContext: schema.NoopContext,
+		}
+		tenantOnlyResource("vra_test", r)
+
+		diags := r.CreateContext(context.Background(), nil, &Client{organization: tt.organization})
+		if diags.HasError() != tt.err {
+			t.Errorf("tenantOnlyResource for organization %q expected error %t, actual %v", tt.organization, tt.err, diags)
+		}
+		if called == tt.err {
+			t.Errorf("tenantOnlyResource for organization %q expected the create function to be called %t, actual %t", tt.organization, !tt.err, called)
+		}
+		if tt.err && !strings.Contains(diags[0].Summary, "vra_test") {
+			t.Errorf("tenantOnlyResource expected the diagnostic to name the resource, actual %s", diags[0].Summary)
+		}
+	}
+}
+
+func TestSetTenantOnly(t *testing.T) {
+	p := Provider()
+	m := &Client{organization: ProviderOrganization}
+
+	for _, name := range tenantOnlyDataSources {
+		diags := p.DataSourcesMap[name].ReadContext(context.Background(), nil, m)
+		if !diags.HasError() || !strings.Contains(diags[0].Summary, name) {
+			t.Errorf("data source %s expected an error with the provider organization, actual %v", name, diags)
+		}
+	}
+
+	for _, name := range tenantOnlyResources {
+		diags := p.ResourcesMap[name].ReadContext(context.Background(), nil, m)
+		if !diags.HasError() || !strings.Contains(diags[0].Summary, name) {
+			t.Errorf("resource %s expected an error with the provider organization, actual %v", name, diags)
+		}
+	}
+}
+
+func TestCheckProviderOrganization(t *testing.T) {
+	var tests = []struct {
+		organization string
+		product      string
+		err          bool
+	}{
+		{"acme", ProductVRA, false},
+		{"system", ProductVRA, true},
+		{"system", ProductVCFA, false},
+	}
+
+	for _, tt := range tests {
+		c := &Client{organization: tt.organization, product: &productInfo{product: tt.product}}
+		if err := checkProviderOrganization(c); (err != nil) != tt.err {
+			t.Errorf("checkProviderOrganization for organization %q on %s expected error %t, actual %v", tt.organization, tt.product, tt.err, err)
+		}
+	}
+}
@@ -6,6 +6,9 @@ package vra
 
 import (
 	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -15,6 +18,9 @@ var testAccProviders map[string]*schema.Provider
 var testAccProviderVRA *schema.Provider
 
 func init() {
+	// Record or replay the API interactions when running the acceptance tests offline
+	wrapTransport = newRecorderTransport
+
 	testAccProviderVRA = Provider()
 	testAccProviders = map[string]*schema.Provider{
 		"vra": testAccProviderVRA,
@@ -31,7 +37,60 @@ func TestProvider_impl(_ *testing.T) {
 	var _ *schema.Provider = Provider()
 }
 
+// Environment variables holding secrets, which are not written to the cassettes
+var testAccRecorderSecretEnvVarRegexp = regexp.MustCompile(`TOKEN|PASSWORD|SECRET|PRIVATE_KEY|APP_KEY`)
+
+// testAccRecorder selects the cassette of the test when VRA_RECORDER_MODE is set, so that the API interactions
+// are recorded to testdata/cassettes/<test name>.json, or replayed from it without an appliance.
+// In replay mode, the environment variables the test was recorded with are restored.
+func testAccRecorder(t *testing.T) {
+	mode := os.Getenv(recorderModeEnvVar)
+	if mode == "" {
+		return
+	}
+
+	path := filepath.Join("testdata", "cassettes", t.Name()+".json")
+	t.Setenv(recorderCassetteEnvVar, path)
+	resetRecorder(mode, path)
+
+	switch mode {
+	case recorderModeRecord:
+		env := make(map[string]string)
+		for _, e := range os.Environ() {
+			name, value, _ := strings.Cut(e, "=")
+			if !(strings.HasPrefix(name, "VRA_") || strings.HasPrefix(name, "VCFA_")) || strings.HasPrefix(name, "VRA_RECORDER_") {
+				continue
+			}
+			switch {
+			case name == "VRA_URL":
+				value = "https://" + recorderHost
+			case testAccRecorderSecretEnvVarRegexp.MatchString(name):
+				value = redactedValue
+			}
+			env[name] = value
+		}
+
+		r, err := getRecorder(mode, path)
+		if err != nil {
+			t.Fatalf("unable to record the cassette %s: %s", path, err)
+		}
+		if err := r.setEnv(env); err != nil {
+			t.Fatalf("unable to record the cassette %s: %s", path, err)
+		}
+	case recorderModeReplay:
+		c, err := loadCassette(path)
+		if err != nil {
+			t.Fatalf("unable to replay the cassette: %s", err)
+		}
+		for name, value := range c.Env {
+			t.Setenv(name, value)
+		}
+	}
+}
+
 func testAccPreCheck(t *testing.T) {
+	testAccRecorder(t)
+
 	if v := os.Getenv("VRA_URL"); v == "" {
 		t.Fatal("VRA_URL must be set for acceptance tests")
 	}
@@ -42,6 +101,8 @@ func testAccPreCheck(t *testing.T) {
 }
 
 func testAccPreCheckMachine(t *testing.T) {
+	testAccRecorder(t)
+
 	if os.Getenv("VRA_REFRESH_TOKEN") == "" && os.Getenv("VRA_ACCESS_TOKEN") == "" {
 		t.Fatal("VRA_REFRESH_TOKEN or VRA_ACCESS_TOKEN must be set for acceptance tests")
 	}
@@ -64,6 +125,8 @@ func testAccPreCheckMachine(t *testing.T) {
 }
 
 func testAccPreCheckIntegration(t *testing.T) {
+	testAccRecorder(t)
+
 	if v := os.Getenv("VRA_URL"); v == "" {
 		t.Fatal("VRA_URL must be set for acceptance tests")
 	}
@@ -78,6 +141,8 @@ func testAccPreCheckIntegration(t *testing.T) {
 }
 
 func testAccPreCheckContentSharingPolicy(t *testing.T) {
+	testAccRecorder(t)
+
 	if os.Getenv("VRA_REFRESH_TOKEN") == "" && os.Getenv("VRA_ACCESS_TOKEN") == "" {
 		t.Fatal("VRA_REFRESH_TOKEN or VRA_ACCESS_TOKEN must be set for acceptance tests")
 	}
@@ -97,6 +162,8 @@ func testAccPreCheckContentSharingPolicy(t *testing.T) {
 }
 
 func testAccPreCheckLoadBalancer(t *testing.T) {
+	testAccRecorder(t)
+
 	if os.Getenv("VRA_REFRESH_TOKEN") == "" && os.Getenv("VRA_ACCESS_TOKEN") == "" {
 		t.Fatal("VRA_REFRESH_TOKEN or VRA_ACCESS_TOKEN must be set for acceptance tests")
 	}
@@ -121,6 +188,8 @@ func testAccPreCheckLoadBalancer(t *testing.T) {
 }
 
 func testAccPreCheckBlockDevice(t *testing.T) {
+	testAccRecorder(t)
+
 	if os.Getenv("VRA_REFRESH_TOKEN") == "" && os.Getenv("VRA_ACCESS_TOKEN") == "" {
 		t.Fatal("VRA_REFRESH_TOKEN or VRA_ACCESS_TOKEN must be set for acceptance tests")
 	}
@@ -139,6 +208,8 @@ func testAccPreCheckBlockDevice(t *testing.T) {
 }
 
 func testAccPreCheckBlockDeviceSnapshotResource(t *testing.T) {
+	testAccRecorder(t)
+
 	if os.Getenv("VRA_REFRESH_TOKEN") == "" && os.Getenv("VRA_ACCESS_TOKEN") == "" {
 		t.Fatal("VRA_REFRESH_TOKEN or VRA_ACCESS_TOKEN must be set for acceptance tests")
 	}
@@ -160,6 +231,8 @@ func testAccPreCheckBlockDeviceSnapshotResource(t *testing.T) {
 }
 
 func testAccPreCheckImageProfile(t *testing.T) {
+	testAccRecorder(t)
+
 	if os.Getenv("VRA_REFRESH_TOKEN") == "" && os.Getenv("VRA_ACCESS_TOKEN") == "" {
 		t.Fatal("VRA_REFRESH_TOKEN or VRA_ACCESS_TOKEN must be set for acceptance tests")
 	}
@@ -182,6 +255,8 @@ func testAccPreCheckImageProfile(t *testing.T) {
 }
 
 func testAccPreCheckStorageProfile(t *testing.T) {
+	testAccRecorder(t)
+
 	if os.Getenv("VRA_REFRESH_TOKEN") == "" && os.Getenv("VRA_ACCESS_TOKEN") == "" {
 		t.Fatal("VRA_REFRESH_TOKEN or VRA_ACCESS_TOKEN must be set for acceptance tests")
 	}
@@ -205,6 +280,8 @@ func testAccPreCheckStorageProfile(t *testing.T) {
 }
 
 func testAccPreCheckAWS(t *testing.T) {
+	testAccRecorder(t)
+
 	if v := os.Getenv("VRA_URL"); v == "" {
 		t.Fatal("VRA_URL must be set for acceptance tests")
 	}
@@ -221,6 +298,8 @@ func testAccPreCheckAWS(t *testing.T) {
 }
 
 func testAccPreCheckAzure(t *testing.T) {
+	testAccRecorder(t)
+
 	if os.Getenv("VRA_REFRESH_TOKEN") == "" && os.Getenv("VRA_ACCESS_TOKEN") == "" {
 		t.Fatal("VRA_REFRESH_TOKEN or VRA_ACCESS_TOKEN must be set for acceptance tests")
 	}
@@ -241,6 +320,8 @@ func testAccPreCheckAzure(t *testing.T) {
 }
 
 func testAccPreCheckStorageProfileAws(t *testing.T) {
+	testAccRecorder(t)
+
 	if os.Getenv("VRA_REFRESH_TOKEN") == "" && os.Getenv("VRA_ACCESS_TOKEN") == "" {
 		t.Fatal("VRA_REFRESH_TOKEN or VRA_ACCESS_TOKEN must be set for acceptance tests")
 	}
@@ -259,6 +340,8 @@ func testAccPreCheckStorageProfileAws(t *testing.T) {
 }
 
 func testAccPreCheckStorageProfileAzure(t *testing.T) {
+	testAccRecorder(t)
+
 	if os.Getenv("VRA_REFRESH_TOKEN") == "" && os.Getenv("VRA_ACCESS_TOKEN") == "" {
 		t.Fatal("VRA_REFRESH_TOKEN or VRA_ACCESS_TOKEN must be set for acceptance tests")
 	}
@@ -280,6 +363,8 @@ func testAccPreCheckStorageProfileAzure(t *testing.T) {
 }
 
 func testAccPreCheckStorageProfileVsphere(t *testing.T) {
+	testAccRecorder(t)
+
 	if os.Getenv("VRA_REFRESH_TOKEN") == "" && os.Getenv("VRA_ACCESS_TOKEN") == "" {
 		t.Fatal("VRA_REFRESH_TOKEN or VRA_ACCESS_TOKEN must be set for acceptance tests")
 	}
@@ -298,6 +383,8 @@ func testAccPreCheckStorageProfileVsphere(t *testing.T) {
 }
 
 func testAccPreCheckVsphere(t *testing.T) {
+	testAccRecorder(t)
+
 	if os.Getenv("VRA_REFRESH_TOKEN") == "" && os.Getenv("VRA_ACCESS_TOKEN") == "" {
 		t.Fatal("VRA_REFRESH_TOKEN or VRA_ACCESS_TOKEN must be set for acceptance tests")
 	}
@@ -317,6 +404,8 @@ func testAccPreCheckVsphere(t *testing.T) {
 }
 
 func testAccPreCheckVsphereForDataStore(t *testing.T) {
+	testAccRecorder(t)
+
 	testAccPreCheckVra(t)
 
 	// The vCenter should have already been added into the vRA
@@ -333,6 +422,8 @@ func testAccPreCheckVsphereForDataStore(t *testing.T) {
 }
 
 func testAccPreCheckVsphereForStoragePolicy(t *testing.T) {
+	testAccRecorder(t)
+
 	testAccPreCheckVra(t)
 
 	// The vCenter should have already been added into the vRA
@@ -350,6 +441,8 @@ func testAccPreCheckVsphereForStoragePolicy(t *testing.T) {
 }
 
 func testAccPreCheckBlockDeviceSnapshot(t *testing.T) {
+	testAccRecorder(t)
+
 	testAccPreCheckVra(t)
 
 	// The vCenter should have already been added into the vRA
@@ -366,6 +459,8 @@ func testAccPreCheckBlockDeviceSnapshot(t *testing.T) {
 }
 
 func testAccPreCheckGCP(t *testing.T) {
+	testAccRecorder(t)
+
 	if os.Getenv("VRA_REFRESH_TOKEN") == "" && os.Getenv("VRA_ACCESS_TOKEN") == "" {
 		t.Fatal("VRA_REFRESH_TOKEN or VRA_ACCESS_TOKEN must be set for acceptance tests")
 	}
@@ -386,6 +481,8 @@ func testAccPreCheckGCP(t *testing.T) {
 }
 
 func testAccPreCheckVMC(t *testing.T) {
+	testAccRecorder(t)
+
 	if os.Getenv("VRA_REFRESH_TOKEN") == "" && os.Getenv("VRA_ACCESS_TOKEN") == "" {
 		t.Fatal("VRA_REFRESH_TOKEN or VRA_ACCESS_TOKEN must be set for acceptance tests")
 	}
@@ -409,6 +506,8 @@ func testAccPreCheckVMC(t *testing.T) {
 }
 
 func testAccPreCheckNSXV(t *testing.T) {
+	testAccRecorder(t)
+
 	if os.Getenv("VRA_REFRESH_TOKEN") == "" && os.Getenv("VRA_ACCESS_TOKEN") == "" {
 		t.Fatal("VRA_REFRESH_TOKEN or VRA_ACCESS_TOKEN must be set for acceptance tests")
 	}
@@ -429,6 +528,8 @@ func testAccPreCheckNSXV(t *testing.T) {
 }
 
 func testAccPreCheckNSXT(t *testing.T) {
+	testAccRecorder(t)
+
 	if os.Getenv("VRA_REFRESH_TOKEN") == "" && os.Getenv("VRA_ACCESS_TOKEN") == "" {
 		t.Fatal("VRA_REFRESH_TOKEN or VRA_ACCESS_TOKEN must be set for acceptance tests")
 	}
@@ -449,6 +550,8 @@ func testAccPreCheckNSXT(t *testing.T) {
 }
 
 func testAccPreCheckCatalogItem(t *testing.T) {
+	testAccRecorder(t)
+
 	if os.Getenv("VRA_REFRESH_TOKEN") == "" && os.Getenv("VRA_ACCESS_TOKEN") == "" {
 		t.Fatal("VRA_REFRESH_TOKEN or VRA_ACCESS_TOKEN must be set for acceptance tests")
 	}
@@ -466,6 +569,8 @@ func testAccPreCheckCatalogItem(t *testing.T) {
 }
 
 func testAccPreCheckDeployment(t *testing.T) {
+	testAccRecorder(t)
+
 	if os.Getenv("VRA_REFRESH_TOKEN") == "" && os.Getenv("VRA_ACCESS_TOKEN") == "" {
 		t.Fatal("VRA_REFRESH_TOKEN or VRA_ACCESS_TOKEN must be set for acceptance tests")
 	}
@@ -486,6 +591,8 @@ func testAccPreCheckDeployment(t *testing.T) {
 }
 
 func testAccPreCheckDeploymentDataSource(t *testing.T) {
+	testAccRecorder(t)
+
 	if os.Getenv("VRA_REFRESH_TOKEN") == "" && os.Getenv("VRA_ACCESS_TOKEN") == "" {
 		t.Fatal("VRA_REFRESH_TOKEN or VRA_ACCESS_TOKEN must be set for acceptance tests")
 	}
@@ -502,6 +609,8 @@ func testAccPreCheckDeploymentDataSource(t *testing.T) {
 }
 
 func testAccPreCheckBlueprint(t *testing.T) {
+	testAccRecorder(t)
+
 	if os.Getenv("VRA_REFRESH_TOKEN") == "" && os.Getenv("VRA_ACCESS_TOKEN") == "" {
 		t.Fatal("VRA_REFRESH_TOKEN or VRA_ACCESS_TOKEN must be set for acceptance tests")
 	}
@@ -518,6 +627,8 @@ func testAccPreCheckBlueprint(t *testing.T) {
 }
 
 func testAccPreCheckVra(t *testing.T) {
+	testAccRecorder(t)
+
 	if v := os.Getenv("VRA_URL"); v == "" {
 		t.Fatal("VRA_URL must be set for acceptance tests")
 	}
@@ -528,6 +639,8 @@ func testAccPreCheckVra(t *testing.T) {
 }
 
 func testAccPreCheckContentSource(t *testing.T) {
+	testAccRecorder(t)
+
 	if os.Getenv("VRA_REFRESH_TOKEN") == "" && os.Getenv("VRA_ACCESS_TOKEN") == "" {
 		t.Fatal("VRA_REFRESH_TOKEN or VRA_ACCESS_TOKEN must be set for acceptance tests")
 	}
@@ -548,6 +661,8 @@ func testAccPreCheckContentSource(t *testing.T) {
 }
 
 func testAccPreCheckFabricStorageAccountAzure(t *testing.T) {
+	testAccRecorder(t)
+
 	if os.Getenv("VRA_REFRESH_TOKEN") == "" && os.Getenv("VRA_ACCESS_TOKEN") == "" {
 		t.Fatal("VRA_REFRESH_TOKEN or VRA_ACCESS_TOKEN must be set for acceptance tests")
 	}
@@ -567,6 +682,8 @@ func testAccPreCheckFabricStorageAccountAzure(t *testing.T) {
 }
 
 func testAccPreCheckFabricCompute(t *testing.T) {
+	testAccRecorder(t)
+
 	if os.Getenv("VRA_REFRESH_TOKEN") == "" && os.Getenv("VRA_ACCESS_TOKEN") == "" {
 		t.Fatal("VRA_REFRESH_TOKEN or VRA_ACCESS_TOKEN must be set for acceptance tests")
 	}
@@ -584,6 +701,8 @@ func testAccPreCheckFabricCompute(t *testing.T) {
 }
 
 func testAccPreCheckFabricDatastoreVsphere(t *testing.T) {
+	testAccRecorder(t)
+
 	if os.Getenv("VRA_REFRESH_TOKEN") == "" && os.Getenv("VRA_ACCESS_TOKEN") == "" {
 		t.Fatal("VRA_REFRESH_TOKEN or VRA_ACCESS_TOKEN must be set for acceptance tests")
 	}
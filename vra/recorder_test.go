@@ -0,0 +1,349 @@
+// © Broadcom. All Rights Reserved.
+// The term “Broadcom” refers to Broadcom Inc. and/or its subsidiaries.
+// SPDX-License-Identifier: MPL-2.0
+
+package vra
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Environment variables selecting whether the API interactions are recorded to or replayed from a cassette file.
+// This is used to run the acceptance tests without an appliance.
+const (
+	recorderModeEnvVar     = "VRA_RECORDER_MODE"
+	recorderCassetteEnvVar = "VRA_RECORDER_CASSETTE"
+)
+
+const (
+	recorderModeRecord = "record"
+	recorderModeReplay = "replay"
+
+	// recorderHost replaces the host of the appliance in the cassettes
+	recorderHost = "vra.example.com"
+)
+
+// Response headers kept in the cassettes, all others are scrubbed
+var recordedHeaders = []string{"Content-Type", "Location", "Retry-After"}
+
+type cassette struct {
+	Env          map[string]string      `json:"env,omitempty"`
+	Interactions []*cassetteInteraction `json:"interactions"`
+}
+
+type cassetteInteraction struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	RequestBody string      `json:"request_body,omitempty"`
+	StatusCode  int         `json:"status_code"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        string      `json:"body,omitempty"`
+
+	replayed bool
+}
+
+// recorder records the API interactions to a cassette file or replays them from it.
+// All the transports using the same cassette share the same recorder.
+type recorder struct {
+	mu       sync.Mutex
+	mode     string
+	path     string
+	cassette *cassette
+}
+
+var recorders = struct {
+	sync.Mutex
+	m map[string]*recorder
+}{m: make(map[string]*recorder)}
+
+// recorderFromEnv returns the recorder selected by the environment variables, or nil when recording is disabled.
+func recorderFromEnv() (*recorder, error) {
+	mode := os.Getenv(recorderModeEnvVar)
+	if mode == "" {
+		return nil, nil
+	}
+	if mode != recorderModeRecord && mode != recorderModeReplay {
+		return nil, fmt.Errorf("%s must be either %s or %s, got %s", recorderModeEnvVar, recorderModeRecord, recorderModeReplay, mode)
+	}
+
+	path := os.Getenv(recorderCassetteEnvVar)
+	if path == "" {
+		return nil, fmt.Errorf("%s must be set when %s is set", recorderCassetteEnvVar, recorderModeEnvVar)
+	}
+
+	return getRecorder(mode, path)
+}
+
+func getRecorder(mode, path string) (*recorder, error) {
+	recorders.Lock()
+	defer recorders.Unlock()
+
+	key := mode + ":" + path
+	if r, ok := recorders.m[key]; ok {
+		return r, nil
+	}
+
+	r := &recorder{mode: mode, path: path, cassette: &cassette{}}
+	if mode == recorderModeReplay {
+		c, err := loadCassette(path)
+		if err != nil {
+			return nil, err
+		}
+		r.cassette = c
+	}
+
+	recorders.m[key] = r
+	return r, nil
+}
+
+// resetRecorder discards the state of the recorder of the cassette, so that it is recorded or replayed from the start.
+func resetRecorder(mode, path string) {
+	recorders.Lock()
+	defer recorders.Unlock()
+
+	delete(recorders.m, mode+":"+path)
+}
+
+func loadCassette(path string) (*cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading the cassette %s: %s", path, err)
+	}
+
+	var c cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("error unmarshalling the cassette %s: %s", path, err)
+	}
+	return &c, nil
+}
+
+// setEnv records the environment variables the interactions were recorded with.
+func (r *recorder) setEnv(env map[string]string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cassette.Env = env
+	return r.save()
+}
+
+// save writes the cassette file. Must be called with the lock held.
+func (r *recorder) save() error {
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, data, 0644)
+}
+
+func (r *recorder) record(req *http.Request, requestBody []byte, resp *http.Response, body []byte) error {
+	scrub := func(s string) string {
+		return redactJSONFields(strings.ReplaceAll(s, req.URL.Host, recorderHost))
+	}
+
+	header := make(http.Header)
+	for _, name := range recordedHeaders {
+		for _, value := range resp.Header.Values(name) {
+			header.Add(name, scrub(value))
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, &cassetteInteraction{
+		Method:      req.Method,
+		URL:         recorderURL(req),
+		RequestBody: sensitiveFormFieldRegexp.ReplaceAllString(scrub(string(requestBody)), "${1}"+redactedValue),
+		StatusCode:  resp.StatusCode,
+		Header:      header,
+		Body:        scrub(string(body)),
+	})
+	return r.save()
+}
+
+// replay returns the response of the first interaction not replayed yet matching the request.
+// Interactions matching the full URL take precedence over those matching the path only.
+func (r *recorder) replay(req *http.Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	url := recorderURL(req)
+	path := strings.SplitN(url, "?", 2)[0]
+
+	var interaction *cassetteInteraction
+	for _, i := range r.cassette.Interactions {
+		if !i.replayed && i.Method == req.Method && i.URL == url {
+			interaction = i
+			break
+		}
+	}
+	if interaction == nil {
+		for _, i := range r.cassette.Interactions {
+			if !i.replayed && i.Method == req.Method && strings.SplitN(i.URL, "?", 2)[0] == path {
+				interaction = i
+				break
+			}
+		}
+	}
+	if interaction == nil {
+		return nil, fmt.Errorf("no interaction left in the cassette %s for %s %s", r.path, req.Method, url)
+	}
+	interaction.replayed = true
+
+	header := interaction.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.StatusCode, http.StatusText(interaction.StatusCode)),
+		StatusCode:    interaction.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(interaction.Body)),
+		ContentLength: int64(len(interaction.Body)),
+		Request:       req,
+	}, nil
+}
+
+// recorderURL returns the request URL without the scheme and host of the appliance.
+func recorderURL(req *http.Request) string {
+	return req.URL.RequestURI()
+}
+
+// recorderTransport is an http.RoundTripper that records the API interactions to a cassette, or replays them from it.
+type recorderTransport struct {
+	transport http.RoundTripper
+	recorder  *recorder
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *recorderTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.recorder.mode == recorderModeReplay {
+		return t.recorder.replay(req)
+	}
+
+	var requestBody []byte
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		requestBody, err = io.ReadAll(body)
+		body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := t.recorder.record(req, requestBody, resp, body); err != nil {
+		log.Printf("[WARN] Unable to record the interaction to the cassette %s: %s", t.recorder.path, err)
+	}
+	return resp, nil
+}
+
+// newRecorderTransport wraps the transport with the recorder selected by the environment variables, if any.
+// It is injected into the transports of the provider by the tests.
+func newRecorderTransport(transport http.RoundTripper) (http.RoundTripper, error) {
+	r, err := recorderFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return transport, nil
+	}
+	return &recorderTransport{transport: transport, recorder: r}, nil
+}
+
+func TestRecorderTransport(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.Write([]byte(`{"token":"my-access-token","tokenType":"Bearer","self":"http://` + r.Host + r.URL.Path + `","request":` + strconv.Itoa(requests) + `}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	t.Setenv(recorderCassetteEnvVar, path)
+
+	get := func(url string) (string, error) {
+		transport, err := createTransport(TransportOptions{})
+		if err != nil {
+			return "", err
+		}
+		resp, err := (&http.Client{Transport: transport}).Get(url)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		return string(body), err
+	}
+
+	t.Setenv(recorderModeEnvVar, recorderModeRecord)
+	resetRecorder(recorderModeRecord, path)
+	for i := 0; i < 2; i++ {
+		if _, err := get(server.URL + "/iaas/api/zones"); err != nil {
+			t.Fatalf("recording returned error %s", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unable to read the cassette: %s", err)
+	}
+	for _, secret := range []string{"my-access-token", "session=secret", strings.TrimPrefix(server.URL, "http://")} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette was not scrubbed of %s: %s", secret, data)
+		}
+	}
+
+	t.Setenv(recorderModeEnvVar, recorderModeReplay)
+	resetRecorder(recorderModeReplay, path)
+	for i, expected := range []string{`"request":1`, `"request":2`} {
+		body, err := get("https://" + recorderHost + "/iaas/api/zones")
+		if err != nil {
+			t.Fatalf("replay %d returned error %s", i, err)
+		}
+		if !strings.Contains(body, expected) || !strings.Contains(body, `"tokenType":"Bearer"`) {
+			t.Errorf("replay %d expected %s, actual %s", i, expected, body)
+		}
+	}
+
+	if _, err := get("https://" + recorderHost + "/iaas/api/zones"); err == nil {
+		t.Errorf("replay expected an error once the cassette is exhausted")
+	}
+	if requests != 2 {
+		t.Errorf("replay expected no request to the server, actual %d", requests-2)
+	}
+}
@@ -0,0 +1,55 @@
+// © Broadcom. All Rights Reserved.
+// The term “Broadcom” refers to Broadcom Inc. and/or its subsidiaries.
+// SPDX-License-Identifier: MPL-2.0
+
+package vra
+
+import (
+	"regexp"
+	"strings"
+)
+
+const redactedValue = "**REDACTED**"
+
+var (
+	// Headers carrying credentials
+	sensitiveHeaderRegexp = regexp.MustCompile(`(?im)^((?:Proxy-)?Authorization|Cookie|Set-Cookie|X-Xsrf-Token)(:[ \t]*)[^\r\n]*`)
+
+	// JSON string fields whose name suggests a secret, such as refreshToken, password, secretKey or privateKey
+	sensitiveJSONFieldRegexp = regexp.MustCompile(`(?i)("([^"]*(?:password|secret|token|private_?key|api_?key|credential)[^"]*)"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+
+	// JSON fields matching sensitiveJSONFieldRegexp which do not hold a secret
+	nonSensitiveJSONFields = map[string]struct{}{
+		"tokentype":  {},
+		"token_type": {},
+	}
+
+	// Form encoded fields carrying credentials
+	sensitiveFormFieldRegexp = regexp.MustCompile(`(?i)\b((?:refresh_token|access_token|password)=)[^&\s]*`)
+)
+
+// redactHTTPLog masks the credentials in a dump of an http request or response.
+// When omitBody is true, the body of the request or response is left out entirely.
+func redactHTTPLog(s string, omitBody bool) string {
+	if omitBody {
+		if i := strings.Index(s, "\r\n\r\n"); i >= 0 && strings.TrimSpace(s[i:]) != "" {
+			s = s[:i] + "\r\n\r\n[body omitted]"
+		}
+	}
+
+	s = sensitiveHeaderRegexp.ReplaceAllString(s, "${1}${2}"+redactedValue)
+	s = redactJSONFields(s)
+	s = sensitiveFormFieldRegexp.ReplaceAllString(s, "${1}"+redactedValue)
+	return s
+}
+
+// redactJSONFields masks the values of the JSON string fields whose name suggests a secret.
+func redactJSONFields(s string) string {
+	return sensitiveJSONFieldRegexp.ReplaceAllStringFunc(s, func(field string) string {
+		match := sensitiveJSONFieldRegexp.FindStringSubmatch(field)
+		if _, ok := nonSensitiveJSONFields[strings.ToLower(match[2])]; ok {
+			return field
+		}
+		return match[1] + `"` + redactedValue + `"`
+	})
+}
@@ -16,9 +16,12 @@ import (
 	"time"
 
 	"github.com/go-openapi/strfmt"
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/vmware/vra-sdk-go/pkg/client"
 	"github.com/vmware/vra-sdk-go/pkg/client/blueprint"
 	"github.com/vmware/vra-sdk-go/pkg/client/blueprint_requests"
@@ -34,20 +37,33 @@ const (
 	EditTagsDeploymentActionName    = "EditTags"
 	PowerOffDeploymentActionName    = "PowerOff"
 	PowerOnDeploymentActionName     = "PowerOn"
+	DeleteDeploymentActionName      = "Delete"
 	UpdateDeploymentActionName      = "update"
 )
 
+// Name of the input of the Update deployment action which selects the version of the catalog item. The inputs of the
+// day-2 actions are described by the inputs schema returned with the action of each deployment, which is checked for
+// the input during plan before the version is changed in place.
+const UpdateDeploymentActionVersionInputName = "catalogItemVersion"
+
 func resourceDeployment() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceDeploymentCreate,
 		ReadContext:   resourceDeploymentRead,
 		UpdateContext: resourceDeploymentUpdate,
 		DeleteContext: resourceDeploymentDelete,
+		CustomizeDiff: customdiff.Sequence(
+			resourceDeploymentInputsCustomizeDiff,
+			resourceDeploymentCustomizeDiff,
+			resourceDeploymentLeaseCustomizeDiff,
+			resourceDeploymentCatalogItemVersionCustomizeDiff,
+		),
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceDeploymentImportState,
 		},
 
 		Schema: map[string]*schema.Schema{
+			"approval": deploymentApprovalSchema(),
 			"blueprint_id": {
 				Type:          schema.TypeString,
 				Optional:      true,
@@ -79,8 +95,7 @@ func resourceDeployment() *schema.Resource {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Computed:    true,
-				ForceNew:    true,
-				Description: "The version of the catalog item to be used to request the deployment.",
+				Description: "The version of the catalog item to be used to request the deployment. Changing the version updates the deployment in place with the Update action when the inputs schema of the action has a catalogItemVersion input, otherwise the plan fails.",
 			},
 			"created_at": {
 				Type:        schema.TypeString,
@@ -92,6 +107,18 @@ func resourceDeployment() *schema.Resource {
 				Computed:    true,
 				Description: "The user the entity was created by.",
 			},
+			"delete_retry": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "The number of times a failed delete of the deployment is retried, ignoring the failures to delete its resources. The attempts share the delete timeout.",
+			},
+			"deletion_policy": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{DeploymentDeletionPolicyDelete, DeploymentDeletionPolicyAbandon}, false),
+				Description:  "What happens to the deployment when the resource is destroyed: `delete` deletes the deployment, `abandon` only removes it from the state.",
+			},
 			"description": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -113,14 +140,28 @@ func resourceDeployment() *schema.Resource {
 				Deprecated: "Deprecated. True by default even if not provided.",
 			},
 			"expense": expenseSchema(),
-			"inputs": {
-				Type:        schema.TypeMap,
+			"force_delete": {
+				Type:        schema.TypeBool,
 				Optional:    true,
-				Description: "Inputs provided by the user. For inputs including those with default values, refer to inputs_including_defaults.",
+				Description: "Flag to indicate whether to cancel the request in progress on the deployment and to ignore the failures to delete its resources when the deployment is deleted.",
+			},
+			"inputs": {
+				Type:          schema.TypeMap,
+				Optional:      true,
+				ConflictsWith: []string{"inputs_json"},
+				Description:   "Inputs provided by the user. For inputs including those with default values, refer to inputs_including_defaults.",
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
 				},
 			},
+			"inputs_json": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ConflictsWith:    []string{"inputs"},
+				ValidateFunc:     validateInputsJSON,
+				DiffSuppressFunc: suppressEquivalentInputsJSON,
+				Description:      "Inputs provided by the user as a JSON object, which may contain arrays and objects. The values are converted to the types of the inputs of the catalog item or cloud template.",
+			},
 			"inputs_including_defaults": {
 				Type:        schema.TypeMap,
 				Computed:    true,
@@ -129,7 +170,8 @@ func resourceDeployment() *schema.Resource {
 					Type: schema.TypeString,
 				},
 			},
-			"last_request": deploymentRequestSchema(),
+			"last_request":        deploymentRequestSchema(),
+			"last_request_events": deploymentRequestEventsSchema(),
 			"last_updated_at": {
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -140,21 +182,46 @@ func resourceDeployment() *schema.Resource {
 				Computed:    true,
 				Description: "The user that last updated the deployment.",
 			},
+			"lease_days": {
+				Type:          schema.TypeInt,
+				Optional:      true,
+				ConflictsWith: []string{"lease_expire_at"},
+				ValidateFunc:  validation.IntAtLeast(1),
+				Description:   "The number of days from now the deployment lease is changed to expire in, when it is changed.",
+			},
 			"lease_expire_at": {
-				Type:        schema.TypeString,
-				Computed:    true,
-				Description: "Date when the deployment lease expire. The date is in ISO 6801 and UTC.",
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ConflictsWith:    []string{"lease_days"},
+				ValidateFunc:     validateLeaseExpireAt,
+				DiffSuppressFunc: suppressEquivalentLeaseExpireAt,
+				Description:      "Date when the deployment lease expire. The date is in ISO 6801 and UTC.",
 			},
 			"name": {
 				Type:        schema.TypeString,
 				Required:    true,
 				Description: "The name of the deployment.",
 			},
+			"on_approval_pending": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{DeploymentOnApprovalPendingWait, DeploymentOnApprovalPendingFail}, false),
+				Description:  "What happens when a request on the deployment is pending approval: `wait` waits until the request is approved or rejected, `fail` fails right away.",
+			},
 			"org_id": {
 				Type:        schema.TypeString,
 				Computed:    true,
 				Description: "The Id of the organization this deployment belongs to.",
 			},
+			"outputs": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "The outputs of the cloud template of the deployment, with their values encoded in JSON.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
 			"owner": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -172,8 +239,14 @@ func resourceDeployment() *schema.Resource {
 				Optional:    true,
 				Description: "Reason for requesting/updating a blueprint.",
 			},
-			"resources": resourcesSchema(),
-			// TODO: Add plan / simulate feature
+			"planned_changes": deploymentPlannedChangesSchema(),
+			"resources":       resourcesSchema(),
+			"simulate": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Flag to indicate whether to plan the changes to the resources of the deployment during terraform plan. The planned changes are exposed in planned_changes.",
+			},
 			"status": {
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -210,6 +283,10 @@ func resourceDeploymentCreate(ctx context.Context, d *schema.ResourceData, m int
 		return diag.FromErr(errors.New("only one of (blueprint_id, catalog_item_id) required"))
 	}
 
+	if diags := validateDeploymentInputs(d, apiClient, true); diags.HasError() {
+		return diags
+	}
+
 	deploymentName := d.Get("name").(string)
 	projectID := d.Get("project_id").(string)
 
@@ -241,7 +318,7 @@ func resourceDeploymentCreate(ctx context.Context, d *schema.ResourceData, m int
 			Version:        catalogItemVersion,
 		}
 
-		if v, ok := d.GetOk("inputs"); ok {
+		if v, ok := getDeploymentInputs(d); ok {
 			inputs, err = getCatalogItemInputsByType(apiClient, catalogItemID, catalogItemVersion, v)
 			if err != nil {
 				return diag.FromErr(err)
@@ -299,7 +376,7 @@ func resourceDeploymentCreate(ctx context.Context, d *schema.ResourceData, m int
 			blueprintRequest.Description = v.(string)
 		}
 
-		if v, ok := d.GetOk("inputs"); ok {
+		if v, ok := getDeploymentInputs(d); ok {
 			if blueprintContent != "" && blueprintID == "" {
 				inputs = expandInputs(v)
 			} else {
@@ -351,24 +428,28 @@ func resourceDeploymentCreate(ctx context.Context, d *schema.ResourceData, m int
 	}
 
 	stateChangeFunc := retry.StateChangeConf{
-		Delay:      5 * time.Second,
+		Delay:      pollDelay,
 		Pending:    []string{models.DeploymentStatusCREATEINPROGRESS, models.DeploymentStatusUPDATEINPROGRESS},
-		Refresh:    deploymentStatusRefreshFunc(*apiClient, d.Id()),
+		Refresh:    deploymentStatusRefreshFunc(*apiClient, d.Id(), d.Get("on_approval_pending").(string)),
 		Target:     []string{models.DeploymentStatusCREATESUCCESSFUL, models.DeploymentStatusUPDATESUCCESSFUL},
 		Timeout:    d.Timeout(schema.TimeoutCreate),
-		MinTimeout: 5 * time.Second,
+		MinTimeout: pollMinTimeout,
 	}
 
 	deploymentID, err := stateChangeFunc.WaitForStateContext(ctx)
 	if err != nil {
-		readErrors := resourceDeploymentRead(ctx, d, m)
-		if readErrors.HasError() {
-			return append(readErrors, diag.Errorf("failed to create deployment: %v", err.Error())...)
-		}
-		return diag.FromErr(err)
+		failureDiags := deploymentRequestFailureDiagnostics(apiClient, d.Id(), "failed to create deployment", err)
+		return append(resourceDeploymentRead(ctx, d, m), failureDiags...)
 	}
 
 	d.SetId(deploymentID.(string))
+
+	if leaseExpireAt, ok := getDeploymentLeaseExpireAt(d); ok {
+		if err := runChangeLeaseDeploymentAction(ctx, d, apiClient, strfmt.UUID(d.Id()), leaseExpireAt); err != nil {
+			return append(resourceDeploymentRead(ctx, d, m), diag.FromErr(err)...)
+		}
+	}
+
 	log.Printf("Finished to create vra_deployment resource with name %s", d.Get("name"))
 
 	return resourceDeploymentRead(ctx, d, m)
@@ -378,9 +459,6 @@ func resourceDeploymentRead(_ context.Context, d *schema.ResourceData, m interfa
 	log.Printf("Reading the vra_deployment resource with name %s", d.Get("name"))
 	apiClient := m.(*Client).apiClient
 
-	// Getting the input types map
-	inputTypesMap := getInputTypesMap(d, apiClient)
-
 	id := d.Id()
 	expandProject := d.Get("expand_project").(bool)
 
@@ -389,11 +467,8 @@ func resourceDeploymentRead(_ context.Context, d *schema.ResourceData, m interfa
 		expand = append(expand, "project")
 	}
 
-	resp, err := apiClient.Deployments.GetDeploymentByIDV3UsingGET(
-		deployments.NewGetDeploymentByIDV3UsingGETParams().
-			WithDeploymentID(strfmt.UUID(id)).
-			WithExpand(expand).
-			WithAPIVersion(withString(DeploymentsAPIVersion)))
+	// The outputs are returned with the deployment, so that they are refreshed without another request
+	resp, outputs, err := getDeploymentWithOutputs(apiClient, id, expand)
 	if err != nil {
 		switch err.(type) {
 		case *deployments.GetDeploymentByIDV3UsingGETNotFound:
@@ -403,11 +478,16 @@ func resourceDeploymentRead(_ context.Context, d *schema.ResourceData, m interfa
 		return diag.FromErr(err)
 	}
 
-	deployment := *resp.Payload
+	deployment := *resp
 	d.Set("blueprint_id", deployment.BlueprintID)
 	d.Set("blueprint_version", deployment.BlueprintVersion)
 	d.Set("catalog_item_id", deployment.CatalogItemID)
 	d.Set("catalog_item_version", deployment.CatalogItemVersion)
+
+	// Getting the input types map of the current blueprint or catalog item version of the deployment, which may have
+	// been updated outside of Terraform
+	inputTypesMap := getInputTypesMap(d, apiClient)
+
 	d.Set("created_at", deployment.CreatedAt.String())
 	d.Set("created_by", deployment.CreatedBy)
 	d.Set("description", deployment.Description)
@@ -421,6 +501,18 @@ func resourceDeploymentRead(_ context.Context, d *schema.ResourceData, m interfa
 	}
 
 	allInputs := expandInputs(deployment.Inputs)
+	if v, ok := d.GetOk("inputs_json"); ok {
+		userInputs, err := expandInputsJSON(v.(string))
+		if err != nil {
+			return diag.Errorf("error decoding deployment inputs_json - error: %#v", err)
+		}
+		inputsJSON, err := flattenInputsJSON(allInputs, userInputs, inputTypesMap)
+		if err != nil {
+			return diag.Errorf("error setting deployment inputs_json - error: %#v", err)
+		}
+		d.Set("inputs_json", inputsJSON)
+	}
+
 	if v, ok := d.GetOk("inputs"); ok {
 		userInputs := v.(map[string]interface{})
 		if err := d.Set("inputs", updateUserInputs(allInputs, userInputs, inputTypesMap)); err != nil {
@@ -432,6 +524,34 @@ func resourceDeploymentRead(_ context.Context, d *schema.ResourceData, m interfa
 		return diag.Errorf("error setting deployment last_request - error: %#v", err)
 	}
 
+	var approval *deploymentApproval
+	if isDeploymentRequestApprovalRequired(deployment.LastRequest) {
+		approval = getDeploymentRequestApprovalOrNil(apiClient, deployment.LastRequest.ID)
+	}
+
+	if err := d.Set("approval", flattenDeploymentApproval(approval)); err != nil {
+		return diag.Errorf("error setting deployment approval - error: %#v", err)
+	}
+
+	// The events are informational, so failing to retrieve them does not fail the refresh of the deployment
+	lastRequestEvents := make([]*models.Event, 0)
+	if deployment.LastRequest != nil {
+		events, err := getDeploymentRequestEvents(apiClient, deployment.LastRequest.ID)
+		if err != nil {
+			log.Printf("[WARN] Unable to retrieve the events of the last request of deployment %s: %s", d.Id(), err)
+		} else {
+			lastRequestEvents = events
+		}
+	}
+
+	if err := d.Set("last_request_events", flattenDeploymentRequestEvents(lastRequestEvents)); err != nil {
+		return diag.Errorf("error setting deployment last_request_events - error: %#v", err)
+	}
+
+	// The planned changes are only set by the plan when simulate is enabled. They are reset once applied, so that the
+	// changes of a past plan are not kept in the state.
+	d.Set("planned_changes", make([]map[string]interface{}, 0))
+
 	d.Set("last_updated_at", deployment.LastUpdatedAt.String())
 	d.Set("last_updated_by", deployment.LastUpdatedBy)
 	d.Set("lease_expire_at", deployment.LeaseExpireAt.String())
@@ -439,6 +559,10 @@ func resourceDeploymentRead(_ context.Context, d *schema.ResourceData, m interfa
 	d.Set("org_id", deployment.OrgID)
 	d.Set("owner", deployment.OwnedBy)
 
+	if err := d.Set("outputs", flattenDeploymentOutputs(outputs)); err != nil {
+		return diag.Errorf("error setting deployment outputs - error: %#v", err)
+	}
+
 	if err := d.Set("project", flattenResourceReference(deployment.Project)); err != nil {
 		return diag.Errorf("error setting project in deployment - error: %#v", err)
 	}
@@ -469,6 +593,12 @@ func resourceDeploymentUpdate(ctx context.Context, d *schema.ResourceData, m int
 	log.Printf("Starting to update the vra_deployment resource with name %s", d.Get("name"))
 	apiClient := m.(*Client).apiClient
 
+	if d.HasChanges("blueprint_id", "blueprint_version", "catalog_item_version", "inputs", "inputs_json") {
+		if diags := validateDeploymentInputs(d, apiClient, false); diags.HasError() {
+			return diags
+		}
+	}
+
 	_, blueprintContentExists := d.GetOk("blueprint_content")
 
 	if d.HasChange("blueprint_id") || d.HasChange("blueprint_version") || blueprintContentExists {
@@ -486,27 +616,24 @@ func resourceDeploymentUpdate(ctx context.Context, d *schema.ResourceData, m int
 			}
 		}
 
-		if d.HasChange("inputs") {
+		if d.HasChanges("inputs", "inputs_json", "catalog_item_version") {
 			err := runDeploymentUpdateAction(ctx, d, apiClient, deploymentUUID)
 			if err != nil {
 				return diag.FromErr(err)
 			}
 
 			stateChangeFunc := retry.StateChangeConf{
-				Delay:      5 * time.Second,
+				Delay:      pollDelay,
 				Pending:    []string{models.DeploymentStatusCREATEINPROGRESS, models.DeploymentStatusUPDATEINPROGRESS},
-				Refresh:    deploymentStatusRefreshFunc(*apiClient, d.Id()),
+				Refresh:    deploymentStatusRefreshFunc(*apiClient, d.Id(), d.Get("on_approval_pending").(string)),
 				Target:     []string{models.DeploymentStatusCREATESUCCESSFUL, models.DeploymentStatusUPDATESUCCESSFUL},
 				Timeout:    d.Timeout(schema.TimeoutCreate),
-				MinTimeout: 5 * time.Second,
+				MinTimeout: pollMinTimeout,
 			}
 
 			if _, err := stateChangeFunc.WaitForStateContext(ctx); err != nil {
-				readErrors := resourceDeploymentRead(ctx, d, m)
-				if readErrors.HasError() {
-					return append(readErrors, diag.Errorf("failed to update deployment: %v", err.Error())...)
-				}
-				return diag.FromErr(err)
+				failureDiags := deploymentRequestFailureDiagnostics(apiClient, d.Id(), "failed to update deployment", err)
+				return append(resourceDeploymentRead(ctx, d, m), failureDiags...)
 			}
 		}
 	}
@@ -519,6 +646,14 @@ func resourceDeploymentUpdate(ctx context.Context, d *schema.ResourceData, m int
 		}
 	}
 
+	if deploymentLeaseChanged(d) {
+		leaseExpireAt, _ := getDeploymentLeaseExpireAt(d)
+		err := runChangeLeaseDeploymentAction(ctx, d, apiClient, strfmt.UUID(d.Id()), leaseExpireAt)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	log.Printf("Finished updating the vra_deployment resource with name %s", d.Get("name"))
 	return resourceDeploymentRead(ctx, d, m)
 }
@@ -527,24 +662,33 @@ func resourceDeploymentDelete(ctx context.Context, d *schema.ResourceData, m int
 	log.Printf("Starting to delete the vra_deployment resource with name %s", d.Get("name"))
 	apiClient := m.(*Client).apiClient
 
-	id := d.Id()
-	_, err := apiClient.Deployments.DeleteDeploymentUsingDELETE2(deployments.NewDeleteDeploymentUsingDELETE2Params().WithDeploymentID(strfmt.UUID(id)))
-	if err != nil {
-		return diag.FromErr(err)
+	if d.Get("deletion_policy").(string) == DeploymentDeletionPolicyAbandon {
+		log.Printf("[WARN] Abandoning the vra_deployment resource with name %s, the deployment is removed from the state but not deleted", d.Get("name"))
+		d.SetId("")
+		return nil
 	}
 
-	log.Printf("Requested for deleting the vra_deployment resource with name %s", d.Get("name"))
+	deploymentUUID := strfmt.UUID(d.Id())
+	deadline := time.Now().Add(d.Timeout(schema.TimeoutDelete))
+	deleteRetry := d.Get("delete_retry").(int)
 
-	stateChangeFunc := retry.StateChangeConf{
-		Delay:      5 * time.Second,
-		Pending:    []string{reflect.TypeOf((*deployments.GetDeploymentByIDV3UsingGETOK)(nil)).String()},
-		Refresh:    deploymentDeleteStatusRefreshFunc(*apiClient, d.Id()),
-		Target:     []string{reflect.TypeOf((*deployments.GetDeploymentByIDV3UsingGETNotFound)(nil)).String()},
-		Timeout:    d.Timeout(schema.TimeoutDelete),
-		MinTimeout: 5 * time.Second,
+	// The attempts share the timeout of the delete, so that the retries are not cut short by the deadline
+	var err error
+	if d.Get("force_delete").(bool) {
+		err = forceDeleteDeployment(ctx, apiClient, deploymentUUID, deploymentDeleteAttemptTimeout(deadline, deleteRetry+1))
+	} else {
+		err = deleteDeployment(ctx, apiClient, deploymentUUID, deploymentDeleteAttemptTimeout(deadline, deleteRetry+1))
 	}
 
-	if _, err = stateChangeFunc.WaitForStateContext(ctx); err != nil {
+	for attempt := 1; err != nil && attempt <= deleteRetry; attempt++ {
+		timeout := deploymentDeleteAttemptTimeout(deadline, deleteRetry-attempt+1)
+		if timeout <= 0 {
+			break
+		}
+		log.Printf("[WARN] Failed to delete the vra_deployment resource with name %s: %s. Retrying ignoring delete failures (%d/%d)", d.Get("name"), err, attempt, deleteRetry)
+		err = deleteDeploymentIgnoringFailures(ctx, apiClient, deploymentUUID, timeout)
+	}
+	if err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -557,7 +701,7 @@ func resourceDeploymentDelete(ctx context.Context, d *schema.ResourceData, m int
 func getInputTypesMap(d *schema.ResourceData, apiClient *client.API) map[string]string {
 	inputTypesMap := make(map[string]string)
 
-	if _, ok := d.GetOk("inputs"); !ok {
+	if _, ok := getDeploymentInputs(d); !ok {
 		return inputTypesMap
 	}
 
@@ -654,6 +798,20 @@ func getBlueprintInputTypesMap(apiClient *client.API, blueprintID string, bluepr
 }
 
 func getCatalogItemSchema(apiClient *client.API, catalogItemID string, catalogItemVersion string) (map[string]interface{}, error) {
+	catalogItemSchema, err := getCatalogItemInputsSchema(apiClient, catalogItemID, catalogItemVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	if catalogItemSchema != nil && catalogItemSchema["properties"] != nil {
+		inputsSchemaMap := catalogItemSchema["properties"].(map[string]interface{})
+		return inputsSchemaMap, nil
+	}
+	return make(map[string]interface{}), nil
+}
+
+// getCatalogItemInputsSchema returns the JSON schema of the inputs of the catalog item, including the required inputs
+func getCatalogItemInputsSchema(apiClient *client.API, catalogItemID string, catalogItemVersion string) (map[string]interface{}, error) {
 	// Getting the catalog item schema
 	log.Printf("Getting the schema for catalog item: %v version: %v", catalogItemID, catalogItemVersion)
 	var catalogItemSchema interface{}
@@ -671,36 +829,44 @@ func getCatalogItemSchema(apiClient *client.API, catalogItemID string, catalogIt
 		catalogItemSchema = getVersionResp.GetPayload().Schema
 	}
 
-	if catalogItemSchema != nil && (catalogItemSchema.(map[string]interface{}))["properties"] != nil {
-		inputsSchemaMap := (catalogItemSchema.(map[string]interface{}))["properties"].(map[string]interface{})
-		return inputsSchemaMap, nil
-	}
-	return make(map[string]interface{}), nil
+	inputsSchema, _ := catalogItemSchema.(map[string]interface{})
+	return inputsSchema, nil
 }
 
 func getBlueprintSchema(apiClient *client.API, blueprintID string, blueprintVersion string) (map[string]models.PropertyDefinition, error) {
+	blueprintInputsSchema, err := getBlueprintInputsSchema(apiClient, blueprintID, blueprintVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	if blueprintInputsSchema != nil && blueprintInputsSchema.Properties != nil {
+		return blueprintInputsSchema.Properties, nil
+	}
+	return make(map[string]models.PropertyDefinition), nil
+}
+
+// getBlueprintInputsSchema returns the schema of the inputs of the blueprint, including the required inputs
+func getBlueprintInputsSchema(apiClient *client.API, blueprintID string, blueprintVersion string) (*models.PropertyDefinition, error) {
 	// Getting the blueprint inputs schema
-	log.Printf("Getting the schema for catalog item: %v version: %v", blueprintID, blueprintVersion)
-	var blueprintInputsSchema map[string]models.PropertyDefinition
+	log.Printf("Getting the schema for blueprint: %v version: %v", blueprintID, blueprintVersion)
 	if blueprintVersion == "" {
 		getItemResp, err := apiClient.Blueprint.GetBlueprintInputsSchemaUsingGET1(blueprint.NewGetBlueprintInputsSchemaUsingGET1Params().WithBlueprintID(blueprintID))
 		if err != nil {
 			return nil, err
 		}
-		blueprintInputsSchema = getItemResp.GetPayload().Properties
-	} else {
-		getVersionResp, err := apiClient.Blueprint.GetBlueprintVersionInputsSchemaUsingGET1(
-			blueprint.NewGetBlueprintVersionInputsSchemaUsingGET1Params().WithBlueprintID(blueprintID).
-				WithVersion(blueprintVersion))
-		if err != nil {
-			return nil, err
-		}
-		blueprintInputsSchema = getVersionResp.GetPayload().Properties
+		return getItemResp.GetPayload(), nil
+	}
+
+	getVersionResp, err := apiClient.Blueprint.GetBlueprintVersionInputsSchemaUsingGET1(
+		blueprint.NewGetBlueprintVersionInputsSchemaUsingGET1Params().WithBlueprintID(blueprintID).
+			WithVersion(blueprintVersion))
+	if err != nil {
+		return nil, err
 	}
-	return blueprintInputsSchema, nil
+	return getVersionResp.GetPayload(), nil
 }
 
-func deploymentStatusRefreshFunc(apiClient client.API, id string) retry.StateRefreshFunc {
+func deploymentStatusRefreshFunc(apiClient client.API, id string, onApprovalPending string) retry.StateRefreshFunc {
 	return func() (interface{}, string, error) {
 		ret, err := apiClient.Deployments.GetDeploymentByIDV3UsingGET(
 			deployments.NewGetDeploymentByIDV3UsingGETParams().
@@ -712,6 +878,18 @@ func deploymentStatusRefreshFunc(apiClient client.API, id string) retry.StateRef
 		}
 
 		status := ret.Payload.Status
+		if lastRequest := ret.Payload.LastRequest; lastRequest != nil {
+			switch lastRequest.Status {
+			case models.RequestStatusAPPROVALPENDING:
+				if onApprovalPending == DeploymentOnApprovalPendingFail {
+					return ret.Payload.ID.String(), status, deploymentApprovalPendingError(&apiClient, lastRequest)
+				}
+				log.Printf("[DEBUG] Request %s of deployment %s is pending approval", lastRequest.ID, id)
+			case models.RequestStatusAPPROVALREJECTED:
+				return ret.Payload.ID.String(), status, deploymentApprovalRejectedError(&apiClient, lastRequest)
+			}
+		}
+
 		switch status {
 		case models.DeploymentStatusCREATEINPROGRESS, models.DeploymentStatusUPDATEINPROGRESS:
 			return ret.Payload.ID.String(), status, nil
@@ -790,7 +968,7 @@ func updateDeploymentWithNewBlueprint(ctx context.Context, d *schema.ResourceDat
 		blueprintRequest.Description = v.(string)
 	}
 
-	if v, ok := d.GetOk("inputs"); ok {
+	if v, ok := getDeploymentInputs(d); ok {
 		if blueprintContent != "" {
 			blueprintRequest.Inputs = expandInputs(v)
 		} else {
@@ -841,20 +1019,17 @@ func updateDeploymentWithNewBlueprint(ctx context.Context, d *schema.ResourceDat
 	}
 
 	stateChangeFunc := retry.StateChangeConf{
-		Delay:      5 * time.Second,
+		Delay:      pollDelay,
 		Pending:    []string{models.DeploymentStatusCREATEINPROGRESS, models.DeploymentStatusUPDATEINPROGRESS},
-		Refresh:    deploymentStatusRefreshFunc(*apiClient, deploymentID),
+		Refresh:    deploymentStatusRefreshFunc(*apiClient, deploymentID, d.Get("on_approval_pending").(string)),
 		Target:     []string{models.DeploymentStatusCREATESUCCESSFUL, models.DeploymentStatusUPDATESUCCESSFUL},
 		Timeout:    d.Timeout(schema.TimeoutCreate),
-		MinTimeout: 5 * time.Second,
+		MinTimeout: pollMinTimeout,
 	}
 
 	if _, err = stateChangeFunc.WaitForStateContext(ctx); err != nil {
-		readErrors := resourceDeploymentRead(ctx, d, m)
-		if readErrors.HasError() {
-			return append(readErrors, diag.Errorf("failed to update deployment: %v", err.Error())...)
-		}
-		return diag.FromErr(err)
+		failureDiags := deploymentRequestFailureDiagnostics(apiClient, d.Id(), "failed to update deployment", err)
+		return append(resourceDeploymentRead(ctx, d, m), failureDiags...)
 	}
 
 	log.Printf("Finished to update vra_deployment '%s' with blueprint '%s'", deploymentName, blueprintID)
@@ -882,8 +1057,52 @@ func updateDeploymentMetadata(d *schema.ResourceData, apiClient *client.API, dep
 	return nil
 }
 
+// resourceDeploymentCatalogItemVersionCustomizeDiff fails the plan when the version of the catalog item of the
+// deployment changes, but the Update action of the deployment has no input selecting the version of the catalog item,
+// rather than replacing the deployment.
+func resourceDeploymentCatalogItemVersionCustomizeDiff(_ context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() == "" || d.HasChange("catalog_item_id") || !d.HasChange("catalog_item_version") || !d.NewValueKnown("catalog_item_version") {
+		return nil
+	}
+
+	supported, err := isDeploymentCatalogItemVersionUpdateSupported(m.(*Client).apiClient, strfmt.UUID(d.Id()))
+	if err != nil {
+		return fmt.Errorf("error checking whether the version of the catalog item of deployment %s can be updated: %s", d.Id(), err)
+	}
+	if !supported {
+		return cty.GetAttrPath("catalog_item_version").NewErrorf(
+			"the version of the catalog item of deployment %s cannot be updated in place: the inputs schema of its 'Update' action has no %q input. Keep the current version, or taint the deployment to replace it",
+			d.Id(), UpdateDeploymentActionVersionInputName)
+	}
+	return nil
+}
+
+// isDeploymentCatalogItemVersionUpdateSupported returns whether the Update action of the deployment has an input
+// selecting the version of the catalog item. The Update action is looked up regardless of whether it is valid in the
+// current state of the deployment, which is checked when the action is run.
+func isDeploymentCatalogItemVersionUpdateSupported(apiClient *client.API, deploymentUUID strfmt.UUID) (bool, error) {
+	deploymentActions, err := apiClient.DeploymentActions.GetDeploymentActionsUsingGET2(deployment_actions.
+		NewGetDeploymentActionsUsingGET2Params().WithDeploymentID(deploymentUUID))
+	if err != nil {
+		return false, err
+	}
+
+	for _, action := range deploymentActions.Payload {
+		if !strings.Contains(strings.ToLower(action.ID), UpdateDeploymentActionName) {
+			continue
+		}
+		actionInputTypesMap, err := getDeploymentActionInputTypesMap(apiClient, deploymentUUID, action.ID)
+		if err != nil {
+			return false, err
+		}
+		_, ok := actionInputTypesMap[UpdateDeploymentActionVersionInputName]
+		return ok, nil
+	}
+	return false, nil
+}
+
 func runDeploymentUpdateAction(ctx context.Context, d *schema.ResourceData, apiClient *client.API, deploymentUUID strfmt.UUID) error {
-	log.Printf("Noticed changes to inputs. Starting to update deployment with inputs")
+	log.Printf("Noticed changes to inputs or catalog item version. Starting to update deployment")
 	// Get the deployment actions
 	deploymentActions, err := apiClient.DeploymentActions.GetDeploymentActionsUsingGET2(deployment_actions.
 		NewGetDeploymentActionsUsingGET2Params().WithDeploymentID(deploymentUUID))
@@ -930,7 +1149,7 @@ func runDeploymentUpdateAction(ctx context.Context, d *schema.ResourceData, apiC
 			catalogItemVersion = v.(string)
 		}
 
-		if v, ok := d.GetOk("inputs"); ok {
+		if v, ok := getDeploymentInputs(d); ok {
 			// If the inputs are provided, get the schema from catalog item to convert the provided input values
 			// to the type defined in the schema.
 			inputs, err = getCatalogItemInputsByType(apiClient, catalogItemID, catalogItemVersion, v)
@@ -952,13 +1171,25 @@ func runDeploymentUpdateAction(ctx context.Context, d *schema.ResourceData, apiC
 				}
 			}
 		}
+
+		if d.HasChange("catalog_item_version") {
+			// The version of the catalog item is selected with an input of the Update action
+			actionInputTypesMap, err := getDeploymentActionInputTypesMap(apiClient, deploymentUUID, actionID)
+			if err != nil {
+				return err
+			}
+			if _, ok := actionInputTypesMap[UpdateDeploymentActionVersionInputName]; !ok {
+				return fmt.Errorf("noticed changes to catalog_item_version, but the 'Update' action of deployment %s does not support changing the version of the catalog item", name)
+			}
+			inputs[UpdateDeploymentActionVersionInputName] = catalogItemVersion
+		}
 	} else if blueprintID != "" {
 		blueprintVersion := ""
 		if v, ok := d.GetOk("blueprint_version"); ok {
 			blueprintVersion = v.(string)
 		}
 
-		if v, ok := d.GetOk("inputs"); ok {
+		if v, ok := getDeploymentInputs(d); ok {
 			// If the inputs are provided, get the schema from blueprint to convert the provided input values
 			// to the type defined in the schema.
 			inputs, err = getBlueprintInputsByType(apiClient, blueprintID, blueprintVersion, v)
@@ -969,6 +1200,9 @@ func runDeploymentUpdateAction(ctx context.Context, d *schema.ResourceData, apiC
 	}
 
 	reason := "Updated deployment inputs from vRA provider for Terraform."
+	if d.HasChange("catalog_item_version") {
+		reason = fmt.Sprintf("Updated deployment to catalog item version %s from vRA provider for Terraform.", d.Get("catalog_item_version"))
+	}
 	err = runAction(ctx, d, apiClient, deploymentUUID, actionID, inputs, reason)
 	if err != nil {
 		return err
@@ -986,6 +1220,13 @@ func getInputsByType(inputs map[string]interface{}, inputTypesMap map[string]str
 	for k, v := range inputs {
 		if t, ok := inputTypesMap[k]; ok {
 			log.Printf("input_key: %s, type: %#v, value provided: %#v", k, t, v)
+			// The values of inputs_json are decoded to their native types already
+			if _, ok := v.(string); !ok {
+				if inputsByType[k], err = convertInputValue(k, v, t); err != nil {
+					return nil, err
+				}
+				continue
+			}
 			switch strings.ToLower(t) {
 			case "array":
 				value := make([]interface{}, 0)
@@ -1158,6 +1399,13 @@ func runChangeOwnerDeploymentAction(ctx context.Context, d *schema.ResourceData,
 }
 
 func runAction(ctx context.Context, d *schema.ResourceData, apiClient *client.API, deploymentUUID strfmt.UUID, actionID string, inputs map[string]interface{}, reason string) error {
+	_, err := submitDeploymentAction(ctx, apiClient, deploymentUUID, actionID, inputs, reason, d.Timeout(schema.TimeoutUpdate), d.Get("on_approval_pending").(string))
+	return err
+}
+
+// submitDeploymentAction submits a day-2 action request on the deployment, waits for its completion and returns the
+// id of the request. A request pending approval fails right away when onApprovalPending is fail.
+func submitDeploymentAction(ctx context.Context, apiClient *client.API, deploymentUUID strfmt.UUID, actionID string, inputs map[string]interface{}, reason string, timeout time.Duration, onApprovalPending string) (strfmt.UUID, error) {
 	resourceActionRequest := models.ResourceActionRequest{
 		ActionID: actionID,
 		Reason:   reason,
@@ -1170,51 +1418,25 @@ func runAction(ctx context.Context, d *schema.ResourceData, apiClient *client.AP
 			WithDeploymentID(deploymentUUID).
 			WithActionRequest(&resourceActionRequest))
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	requestID := resp.GetPayload().ID
 
+	// The request is polled rather than the last request of the deployment, which may be another request submitted
+	// concurrently
 	stateChangeFunc := retry.StateChangeConf{
-		Delay:      5 * time.Second,
-		Pending:    []string{models.RequestStatusPENDING, models.RequestStatusINITIALIZATION, models.RequestStatusCHECKINGAPPROVAL, models.RequestStatusAPPROVALPENDING, models.RequestStatusINPROGRESS},
-		Refresh:    deploymentActionStatusRefreshFunc(*apiClient, deploymentUUID, requestID),
-		Target:     []string{models.RequestStatusCOMPLETION, models.RequestStatusAPPROVALREJECTED, models.RequestStatusABORTED, models.RequestStatusSUCCESSFUL, models.RequestStatusFAILED},
-		Timeout:    d.Timeout(schema.TimeoutUpdate),
-		MinTimeout: 5 * time.Second,
+		Delay:      pollDelay,
+		Pending:    []string{models.RequestStatusCREATED, models.RequestStatusPENDING, models.RequestStatusINITIALIZATION, models.RequestStatusCHECKINGAPPROVAL, models.RequestStatusAPPROVALPENDING, models.RequestStatusINPROGRESS, models.RequestStatusCOMPLETION},
+		Refresh:    requestStatusRefreshFunc(*apiClient, requestID, onApprovalPending),
+		Target:     []string{models.RequestStatusSUCCESSFUL},
+		Timeout:    timeout,
+		MinTimeout: pollMinTimeout,
 	}
 	if _, err = stateChangeFunc.WaitForStateContext(ctx); err != nil {
-		return err
-	}
-	return nil
-}
-
-func deploymentActionStatusRefreshFunc(apiClient client.API, deploymentUUID strfmt.UUID, _ strfmt.UUID) retry.StateRefreshFunc {
-	return func() (interface{}, string, error) {
-		ret, err := apiClient.Deployments.GetDeploymentByIDV3UsingGET(
-			deployments.NewGetDeploymentByIDV3UsingGETParams().
-				WithDeploymentID(deploymentUUID).
-				WithExpand([]string{"lastRequest"}).
-				WithAPIVersion(withString(DeploymentsAPIVersion)))
-		if err != nil {
-			return "", models.RequestStatusFAILED, err
-		}
-
-		status := ret.Payload.LastRequest.Status
-		switch status {
-		case models.RequestStatusPENDING, models.RequestStatusINITIALIZATION, models.RequestStatusCHECKINGAPPROVAL, models.RequestStatusAPPROVALPENDING, models.RequestStatusINPROGRESS, models.RequestStatusCOMPLETION:
-			return [...]string{deploymentUUID.String()}, status, nil
-		case models.RequestStatusAPPROVALREJECTED, models.RequestStatusABORTED:
-			return []string{""}, status, errors.New(ret.Error())
-		case models.RequestStatusFAILED:
-			return [...]string{deploymentUUID.String()}, status, errors.New(ret.Payload.LastRequest.Details)
-		case models.RequestStatusSUCCESSFUL:
-			deploymentID := ret.Payload.ID
-			return deploymentID.String(), status, nil
-		default:
-			return [...]string{deploymentUUID.String()}, ret.Error(), fmt.Errorf("deploymentActionStatusRefreshFunc: unknown status %v", status)
-		}
+		return requestID, err
 	}
+	return requestID, nil
 }
 
 func deploymentDeleteStatusRefreshFunc(apiClient client.API, id string) retry.StateRefreshFunc {
@@ -1254,10 +1476,26 @@ func updateUserInputs(allInputs, userInputs map[string]interface{}, inputTypesMa
 
 	inputs := make(map[string]interface{})
 	for name, value := range userInputs {
-		if value != nil {
-			inputs[name] = decodeInputValue(name, allInputs[name], inputTypesMap)
+		if value == nil {
+			continue
 		}
-		log.Printf("Converted incoming value to string: Key: %v, Value: %v, Converted value: %#v", name, allInputs[name], inputs[name])
+
+		// The inputs removed from the deployment outside of Terraform are left out, so that they show as drift
+		deploymentValue, ok := allInputs[name]
+		if !ok {
+			log.Printf("[DEBUG] Input %s is not set on the deployment", name)
+			continue
+		}
+
+		// The configured value is kept when it is equivalent to the deployment value, such as 1.0 and 1 for a
+		// number, so that only the values changed outside of Terraform show as drift
+		if inputValueUnchanged(name, value, deploymentValue, inputTypesMap) {
+			inputs[name] = value
+			continue
+		}
+
+		inputs[name] = decodeInputValue(name, deploymentValue, inputTypesMap)
+		log.Printf("Converted incoming value to string: Key: %v, Value: %v, Converted value: %#v", name, deploymentValue, inputs[name])
 	}
 
 	return inputs
@@ -0,0 +1,144 @@
+// © Broadcom. All Rights Reserved.
+// The term “Broadcom” refers to Broadcom Inc. and/or its subsidiaries.
+// SPDX-License-Identifier: MPL-2.0
+
+package vra
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/vra-sdk-go/pkg/client/requests"
+)
+
+func resourceDeploymentAction() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceDeploymentActionCreate,
+		ReadContext:   resourceDeploymentActionRead,
+		DeleteContext: resourceDeploymentActionDelete,
+
+		Schema: map[string]*schema.Schema{
+			"action_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The id of the day-2 action run on the deployment.",
+			},
+			"action_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The name of the day-2 action to run on the deployment, such as `PowerOff` or `Deployment.PowerOff`.",
+			},
+			"deployment_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The id of the deployment to run the day-2 action on.",
+			},
+			"inputs": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The inputs of the day-2 action. The values are converted to the types of the inputs schema of the action.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"reason": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The reason for running the day-2 action.",
+			},
+			"request": deploymentRequestSchema(),
+			"triggers": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "A map of arbitrary values which run the day-2 action again when they change.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+		},
+	}
+}
+
+func resourceDeploymentActionCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	apiClient := m.(*Client).apiClient
+
+	deploymentUUID := strfmt.UUID(d.Get("deployment_id").(string))
+	actionName := d.Get("action_name").(string)
+	log.Printf("Starting to run day-2 action %s on deployment %s", actionName, deploymentUUID)
+
+	isActionValid, actionID, err := getDeploymentDay2ActionID(apiClient, deploymentUUID, actionName)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if !isActionValid {
+		return diag.Errorf("%s action is not supported on deployment %s", actionName, deploymentUUID)
+	}
+
+	inputs := make(map[string]interface{})
+	if v, ok := d.GetOk("inputs"); ok {
+		inputs, err = getDeploymentActionInputsByType(apiClient, deploymentUUID, actionID, v)
+		if err != nil {
+			return diag.Errorf("unable to create action inputs for %v. %v", actionID, err.Error())
+		}
+	}
+
+	reason := fmt.Sprintf("Requested %s action from vRA provider for Terraform.", actionID)
+	if v, ok := d.GetOk("reason"); ok {
+		reason = v.(string)
+	}
+
+	requestID, err := submitDeploymentAction(ctx, apiClient, deploymentUUID, actionID, inputs, reason, d.Timeout(schema.TimeoutCreate), DeploymentOnApprovalPendingWait)
+	if err != nil {
+		return diag.Errorf("error running %s action on deployment %s: %s", actionID, deploymentUUID, err)
+	}
+
+	d.SetId(requestID.String())
+	d.Set("action_id", actionID)
+	log.Printf("Finished running day-2 action %s on deployment %s", actionID, deploymentUUID)
+
+	return resourceDeploymentActionRead(ctx, d, m)
+}
+
+func resourceDeploymentActionRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	apiClient := m.(*Client).apiClient
+
+	getResp, err := apiClient.Requests.GetRequestUsingGET2(requests.NewGetRequestUsingGET2Params().WithRequestID(strfmt.UUID(d.Id())))
+	if err != nil {
+		switch err.(type) {
+		case *requests.GetRequestUsingGET2NotFound:
+			// The requests are deleted with the deployment
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	request := getResp.GetPayload()
+	d.Set("action_id", request.ActionID)
+	d.Set("deployment_id", request.DeploymentID.String())
+	if err := d.Set("request", flattenDeploymentRequest(request)); err != nil {
+		return diag.Errorf("error setting deployment action request - error: %#v", err)
+	}
+
+	return nil
+}
+
+func resourceDeploymentActionDelete(_ context.Context, d *schema.ResourceData, _ interface{}) diag.Diagnostics {
+	// A day-2 action cannot be undone, it is only removed from the state
+	d.SetId("")
+	return nil
+}
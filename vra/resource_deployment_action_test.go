@@ -0,0 +1,58 @@
+// © Broadcom. All Rights Reserved.
+// The term “Broadcom” refers to Broadcom Inc. and/or its subsidiaries.
+// SPDX-License-Identifier: MPL-2.0
+
+package vra
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccVRADeploymentAction_Basic(t *testing.T) {
+	rInt := acctest.RandInt()
+	resource1 := "vra_deployment_action.this"
+	deployment := "vra_deployment.this"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheckDeployment(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckVRADeploymentDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckVRADeploymentActionConfig(rInt, "updated description"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair(resource1, "deployment_id", deployment, "id"),
+					resource.TestCheckResourceAttr(resource1, "action_id", "Deployment.EditDeployment"),
+					resource.TestCheckResourceAttr(resource1, "request.0.status", "SUCCESSFUL"),
+				),
+			},
+			{
+				Config: testAccCheckVRADeploymentActionConfig(rInt, "description updated again"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resource1, "triggers.description", "description updated again"),
+					resource.TestCheckResourceAttr(resource1, "request.0.status", "SUCCESSFUL"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckVRADeploymentActionConfig(rInt int, description string) string {
+	return testAccCheckVRADeploymentEmptyConfig(rInt) + fmt.Sprintf(`
+	resource "vra_deployment_action" "this" {
+	  deployment_id = vra_deployment.this.id
+	  action_name   = "EditDeployment"
+
+	  inputs = {
+	    description = "%[1]s"
+	  }
+
+	  triggers = {
+	    description = "%[1]s"
+	  }
+	}`, description)
+}
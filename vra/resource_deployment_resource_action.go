@@ -0,0 +1,291 @@
+// © Broadcom. All Rights Reserved.
+// The term “Broadcom” refers to Broadcom Inc. and/or its subsidiaries.
+// SPDX-License-Identifier: MPL-2.0
+
+package vra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/vmware/vra-sdk-go/pkg/client"
+	"github.com/vmware/vra-sdk-go/pkg/client/deployment_actions"
+	"github.com/vmware/vra-sdk-go/pkg/client/deployments"
+	"github.com/vmware/vra-sdk-go/pkg/client/requests"
+	"github.com/vmware/vra-sdk-go/pkg/models"
+)
+
+func resourceDeploymentResourceAction() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceDeploymentResourceActionCreate,
+		ReadContext:   resourceDeploymentResourceActionRead,
+		DeleteContext: resourceDeploymentResourceActionDelete,
+
+		Schema: map[string]*schema.Schema{
+			"action_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The id of the day-2 action run on the resource.",
+			},
+			"action_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The name of the day-2 action to run on the resource, such as `Resize` or `Cloud.vSphere.Machine.Resize`.",
+			},
+			"deployment_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The id of the deployment of the resource.",
+			},
+			"inputs": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The inputs of the day-2 action. The values are converted to the types of the inputs schema of the action.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"reason": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The reason for running the day-2 action.",
+			},
+			"request": deploymentRequestSchema(),
+			"resource_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The id of the resource the day-2 action is run on.",
+			},
+			"resource_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The name of the resource of the deployment to run the day-2 action on, such as `Cloud_vSphere_Machine_1`.",
+			},
+			"triggers": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "A map of arbitrary values which run the day-2 action again when they change.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+		},
+	}
+}
+
+func resourceDeploymentResourceActionCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	apiClient := m.(*Client).apiClient
+
+	deploymentUUID := strfmt.UUID(d.Get("deployment_id").(string))
+	resourceName := d.Get("resource_name").(string)
+	actionName := d.Get("action_name").(string)
+	log.Printf("Starting to run day-2 action %s on resource %s of deployment %s", actionName, resourceName, deploymentUUID)
+
+	resourceUUID, err := getDeploymentResourceIDByName(apiClient, deploymentUUID, resourceName)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	actionID, err := getDeploymentResourceDay2ActionID(apiClient, deploymentUUID, resourceUUID, actionName)
+	if err != nil {
+		return diag.Errorf("unable to run %s action on resource %s: %s", actionName, resourceName, err)
+	}
+
+	inputs := make(map[string]interface{})
+	if v, ok := d.GetOk("inputs"); ok {
+		inputTypesMap, err := getDeploymentResourceActionInputTypesMap(apiClient, deploymentUUID, resourceUUID, actionID)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		inputs, err = getInputsByType(v.(map[string]interface{}), inputTypesMap)
+		if err != nil {
+			return diag.Errorf("unable to create action inputs for %v. %v", actionID, err.Error())
+		}
+	}
+
+	reason := fmt.Sprintf("Requested %s action on %s from vRA provider for Terraform.", actionID, resourceName)
+	if v, ok := d.GetOk("reason"); ok {
+		reason = v.(string)
+	}
+
+	resourceActionRequest := models.ResourceActionRequest{
+		ActionID: actionID,
+		Reason:   reason,
+		Inputs:   inputs,
+	}
+
+	resp, err := apiClient.DeploymentActions.SubmitResourceActionRequestUsingPOST4(
+		deployment_actions.NewSubmitResourceActionRequestUsingPOST4Params().
+			WithAPIVersion(withString(DeploymentsAPIVersion)).
+			WithDeploymentID(deploymentUUID).
+			WithResourceID(resourceUUID).
+			WithActionRequest(&resourceActionRequest))
+	if err != nil {
+		return diag.Errorf("error running %s action on resource %s: %s", actionID, resourceName, err)
+	}
+
+	requestID := resp.GetPayload().ID
+
+	stateChangeFunc := retry.StateChangeConf{
+		Delay:      pollDelay,
+		Pending:    []string{models.RequestStatusCREATED, models.RequestStatusPENDING, models.RequestStatusINITIALIZATION, models.RequestStatusCHECKINGAPPROVAL, models.RequestStatusAPPROVALPENDING, models.RequestStatusINPROGRESS, models.RequestStatusCOMPLETION},
+		Refresh:    requestStatusRefreshFunc(*apiClient, requestID, DeploymentOnApprovalPendingWait),
+		Target:     []string{models.RequestStatusSUCCESSFUL},
+		Timeout:    d.Timeout(schema.TimeoutCreate),
+		MinTimeout: pollMinTimeout,
+	}
+	if _, err := stateChangeFunc.WaitForStateContext(ctx); err != nil {
+		return diag.Errorf("error running %s action on resource %s: %s", actionID, resourceName, err)
+	}
+
+	d.SetId(requestID.String())
+	d.Set("action_id", actionID)
+	d.Set("resource_id", resourceUUID.String())
+	log.Printf("Finished running day-2 action %s on resource %s of deployment %s", actionID, resourceName, deploymentUUID)
+
+	return resourceDeploymentResourceActionRead(ctx, d, m)
+}
+
+func resourceDeploymentResourceActionRead(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	apiClient := m.(*Client).apiClient
+
+	getResp, err := apiClient.Requests.GetRequestUsingGET2(requests.NewGetRequestUsingGET2Params().WithRequestID(strfmt.UUID(d.Id())))
+	if err != nil {
+		switch err.(type) {
+		case *requests.GetRequestUsingGET2NotFound:
+			// The requests are deleted with the deployment
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	request := getResp.GetPayload()
+	d.Set("action_id", request.ActionID)
+	d.Set("deployment_id", request.DeploymentID.String())
+	if err := d.Set("request", flattenDeploymentRequest(request)); err != nil {
+		return diag.Errorf("error setting deployment resource action request - error: %#v", err)
+	}
+
+	return nil
+}
+
+func resourceDeploymentResourceActionDelete(_ context.Context, d *schema.ResourceData, _ interface{}) diag.Diagnostics {
+	// A day-2 action cannot be undone, it is only removed from the state
+	d.SetId("")
+	return nil
+}
+
+// Returns the id of the resource of the deployment with the given name
+func getDeploymentResourceIDByName(apiClient *client.API, deploymentUUID strfmt.UUID, resourceName string) (strfmt.UUID, error) {
+	getResp, err := apiClient.Deployments.GetDeploymentResourcesUsingGET2(
+		deployments.NewGetDeploymentResourcesUsingGET2Params().
+			WithDeploymentID(deploymentUUID).
+			WithAPIVersion(withString(DeploymentsAPIVersion)).
+			WithDollarTop(withInt32(DefaultDollarTop)))
+	if err != nil {
+		return "", err
+	}
+
+	for _, resource := range getResp.GetPayload().Content {
+		if resource.Name != nil && *resource.Name == resourceName {
+			return resource.ID, nil
+		}
+	}
+	return "", fmt.Errorf("resource %s is not found in deployment %s", resourceName, deploymentUUID)
+}
+
+// Returns the exact action ID for a given action string, if the day2 action is valid currently on the resource.
+// An action whose id or name is the given action string is preferred over an action whose id only contains it.
+func getDeploymentResourceDay2ActionID(apiClient *client.API, deploymentUUID, resourceUUID strfmt.UUID, actionName string) (string, error) {
+	resourceActions, err := apiClient.DeploymentActions.GetResourceActionsUsingGET4(deployment_actions.
+		NewGetResourceActionsUsingGET4Params().WithDeploymentID(deploymentUUID).WithResourceID(resourceUUID))
+	if err != nil {
+		return "", err
+	}
+
+	var resourceAction *models.ResourceAction
+	for _, action := range resourceActions.Payload {
+		if strings.EqualFold(action.ID, actionName) || strings.EqualFold(action.Name, actionName) || strings.HasSuffix(strings.ToLower(action.ID), "."+strings.ToLower(actionName)) {
+			resourceAction = action
+			break
+		}
+		if resourceAction == nil && strings.Contains(strings.ToLower(action.ID), strings.ToLower(actionName)) {
+			resourceAction = action
+		}
+	}
+
+	if resourceAction == nil {
+		return "", fmt.Errorf("%s action is not found in the list of day2 actions allowed on the resource", actionName)
+	}
+	if !resourceAction.Valid {
+		log.Printf("[DEBUG] %s action is not valid based on current state of the resource", resourceAction.ID)
+		return "", fmt.Errorf("%s action is not valid based on current state of the resource", resourceAction.ID)
+	}
+
+	log.Printf("[DEBUG] %s action is available on the resource", resourceAction.ID)
+	return resourceAction.ID, nil
+}
+
+// Gets the inputs and their types for a given resource action id
+func getDeploymentResourceActionInputTypesMap(apiClient *client.API, deploymentUUID, resourceUUID strfmt.UUID, actionID string) (map[string]string, error) {
+	log.Printf("Getting the schema for deploymentID: %v, resourceID: %v, actionID: %v", deploymentUUID, resourceUUID, actionID)
+	resourceAction, err := apiClient.DeploymentActions.GetResourceActionUsingGET4(deployment_actions.
+		NewGetResourceActionUsingGET4Params().WithDeploymentID(deploymentUUID).WithResourceID(resourceUUID).WithActionID(actionID))
+	if err != nil {
+		return nil, err
+	}
+
+	inputsSchemaMap := make(map[string]interface{})
+	if actionSchema, ok := resourceAction.GetPayload().Schema.(map[string]interface{}); ok && actionSchema["properties"] != nil {
+		inputsSchemaMap = actionSchema["properties"].(map[string]interface{})
+	}
+	return getInputTypesMapFromSchema(inputsSchemaMap)
+}
+
+// requestStatusRefreshFunc polls the status of the day-2 action request. A request pending approval fails when
+// onApprovalPending is fail, a rejected request fails with the comment of the approver.
+func requestStatusRefreshFunc(apiClient client.API, requestID strfmt.UUID, onApprovalPending string) retry.StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		ret, err := apiClient.Requests.GetRequestUsingGET2(requests.NewGetRequestUsingGET2Params().WithRequestID(requestID))
+		if err != nil {
+			return "", models.RequestStatusFAILED, err
+		}
+
+		status := ret.Payload.Status
+		switch status {
+		case models.RequestStatusAPPROVALPENDING:
+			if onApprovalPending == DeploymentOnApprovalPendingFail {
+				return requestID.String(), status, deploymentApprovalPendingError(&apiClient, ret.Payload)
+			}
+			log.Printf("[DEBUG] Request %s is pending approval", requestID)
+			return requestID.String(), status, nil
+		case models.RequestStatusCREATED, models.RequestStatusPENDING, models.RequestStatusINITIALIZATION, models.RequestStatusCHECKINGAPPROVAL, models.RequestStatusINPROGRESS, models.RequestStatusCOMPLETION, models.RequestStatusSUCCESSFUL:
+			return requestID.String(), status, nil
+		case models.RequestStatusAPPROVALREJECTED:
+			return requestID.String(), status, deploymentApprovalRejectedError(&apiClient, ret.Payload)
+		case models.RequestStatusABORTED, models.RequestStatusFAILED:
+			return requestID.String(), status, errors.New(ret.Payload.Details)
+		default:
+			return requestID.String(), status, fmt.Errorf("requestStatusRefreshFunc: unknown status %v", status)
+		}
+	}
+}
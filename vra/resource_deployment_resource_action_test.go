@@ -0,0 +1,46 @@
+// © Broadcom. All Rights Reserved.
+// The term “Broadcom” refers to Broadcom Inc. and/or its subsidiaries.
+// SPDX-License-Identifier: MPL-2.0
+
+package vra
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccVRADeploymentResourceAction_Basic(t *testing.T) {
+	rInt := acctest.RandInt()
+	resource1 := "vra_deployment_resource_action.this"
+	deployment := "vra_deployment.this"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheckDeployment(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckVRADeploymentDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckVRADeploymentResourceActionConfig(rInt),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair(resource1, "deployment_id", deployment, "id"),
+					resource.TestCheckResourceAttrPair(resource1, "resource_id", deployment, "resources.0.id"),
+					resource.TestMatchResourceAttr(resource1, "action_id", regexp.MustCompile(`\.PowerOff$`)),
+					resource.TestCheckResourceAttr(resource1, "request.0.status", "SUCCESSFUL"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckVRADeploymentResourceActionConfig(rInt int) string {
+	// Need a deployment whose cloud template has a machine which is powered on
+	return testAccCheckVRADeploymentBlueprintConfig(rInt) + `
+	resource "vra_deployment_resource_action" "this" {
+	  deployment_id = vra_deployment.this.id
+	  resource_name = vra_deployment.this.resources[0].name
+	  action_name   = "PowerOff"
+	}`
+}
@@ -92,6 +92,35 @@ func TestAccVRADeployment_Blueprint(t *testing.T) {
 	})
 }
 
+func TestAccVRADeployment_Lease(t *testing.T) {
+	rInt := acctest.RandInt()
+	resource1 := "vra_deployment.this"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheckDeployment(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckVRADeploymentDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckVRADeploymentLeaseConfig(rInt, 7),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckVRADeploymentExists(resource1),
+					resource.TestCheckResourceAttr(resource1, "lease_days", "7"),
+					resource.TestCheckResourceAttrSet(resource1, "lease_expire_at"),
+				),
+			},
+			{
+				Config: testAccCheckVRADeploymentLeaseConfig(rInt, 14),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckVRADeploymentExists(resource1),
+					resource.TestCheckResourceAttr(resource1, "lease_days", "14"),
+					resource.TestCheckResourceAttr(resource1, "last_request.0.action_id", "Deployment.ChangeLease"),
+				),
+			},
+		},
+	})
+}
+
 func testAccCheckVRADeploymentExists(n string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[n]
@@ -196,6 +225,24 @@ func testAccCheckVRADeploymentBlueprintConfig(rInt int) string {
 	}`, projectName, rInt, blueprintID, blueprintVersion)
 }
 
+func testAccCheckVRADeploymentLeaseConfig(rInt, leaseDays int) string {
+	// Need valid details since this is creating a real deployment without a catalog item and blueprint
+	projectName := os.Getenv("VRA_PROJECT_NAME")
+
+	return fmt.Sprintf(`
+	data "vra_project" "this" {
+	  name = "%s"
+	}
+
+	resource "vra_deployment" "this" {
+	  name        = "test-deployment-%d"
+	  description = "terraform test deployment"
+
+	  project_id = data.vra_project.this.id
+	  lease_days = %d
+	}`, projectName, rInt, leaseDays)
+}
+
 func TestAccVRADeployment_test(t *testing.T) {
 	rInt := acctest.RandInt()
 	resource1 := "vra_deployment.this"
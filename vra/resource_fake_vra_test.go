@@ -0,0 +1,1270 @@
+// © Broadcom. All Rights Reserved.
+// The term “Broadcom” refers to Broadcom Inc. and/or its subsidiaries.
+// SPDX-License-Identifier: MPL-2.0
+
+package vra
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/vmware/vra-sdk-go/pkg/client/deployments"
+	"github.com/vmware/vra-sdk-go/pkg/models"
+)
+
+func TestResourceMachineFakeVRA(t *testing.T) {
+	fake := newFakeVRA(t)
+	m := fake.client(t)
+	r := resourceMachine()
+
+	config := map[string]interface{}{
+		"name":        "machine",
+		"description": "Machine",
+		"flavor":      "small",
+		"image":       "ubuntu",
+		"project_id":  "project-id",
+	}
+
+	state := testResourceApply(t, r, nil, config, m)
+	if state.ID == "" {
+		t.Fatalf("resourceMachineCreate did not set the id")
+	}
+	testCheckResourceAttrs(t, state, map[string]string{
+		"name":        "machine",
+		"description": "Machine",
+		"power_state": models.MachinePowerStateON,
+		"project_id":  "project-id",
+		"owner":       fakeVRAUser,
+	})
+
+	config["description"] = "Updated machine"
+	config["tags"] = []interface{}{map[string]interface{}{"key": "env", "value": "test"}}
+	state = testResourceApply(t, r, state, config, m)
+	testCheckResourceAttrs(t, state, map[string]string{
+		"description": "Updated machine",
+		"tags.#":      "1",
+	})
+
+	imported := testResourceImport(t, r, state.ID, m)
+	for _, key := range []string{"name", "description", "project_id", "external_id", "tags.#"} {
+		if imported.Attributes[key] != state.Attributes[key] {
+			t.Errorf("imported vra_machine attribute %s expected %q, actual %q", key, state.Attributes[key], imported.Attributes[key])
+		}
+	}
+
+	testResourceDestroy(t, r, state, m)
+	if state := testResourceRefresh(t, r, state, m); state != nil {
+		t.Errorf("vra_machine %s still exists after destroy", state.ID)
+	}
+}
+
+func TestResourceDeploymentFakeVRA_CatalogItem(t *testing.T) {
+	fake := newFakeVRA(t)
+	m := fake.client(t)
+	r := resourceDeployment()
+
+	catalogItemID := fake.addCatalogItem("catalog-item", map[string]interface{}{
+		"flavor": map[string]interface{}{"type": "string"},
+		"count":  map[string]interface{}{"type": "integer", "default": 1},
+	}, "1")
+
+	config := map[string]interface{}{
+		"name":                 "deployment",
+		"project_id":           "project-id",
+		"catalog_item_id":      catalogItemID,
+		"catalog_item_version": "1",
+		"inputs": map[string]interface{}{
+			"flavor": "small",
+		},
+	}
+
+	state := testResourceApply(t, r, nil, config, m)
+	if state.ID == "" {
+		t.Fatalf("resourceDeploymentCreate did not set the id")
+	}
+	testCheckResourceAttrs(t, state, map[string]string{
+		"name":                             "deployment",
+		"catalog_item_id":                  catalogItemID,
+		"catalog_item_version":             "1",
+		"status":                           models.DeploymentStatusCREATESUCCESSFUL,
+		"owner":                            fakeVRAUser,
+		"inputs.flavor":                    "small",
+		"inputs_including_defaults.flavor": "small",
+		"inputs_including_defaults.count":  "1",
+	})
+
+	config["description"] = "Updated deployment"
+	config["owner"] = "new-owner@example.com"
+	state = testResourceApply(t, r, state, config, m)
+	testCheckResourceAttrs(t, state, map[string]string{
+		"description":              "Updated deployment",
+		"owner":                    "new-owner@example.com",
+		"last_request.0.action_id": "Deployment.ChangeOwner",
+	})
+
+	imported := testResourceImport(t, r, state.ID, m)
+	for _, key := range []string{"name", "description", "project_id", "catalog_item_id", "catalog_item_version", "owner", "status"} {
+		if imported.Attributes[key] != state.Attributes[key] {
+			t.Errorf("imported vra_deployment attribute %s expected %q, actual %q", key, state.Attributes[key], imported.Attributes[key])
+		}
+	}
+
+	testResourceDestroy(t, r, state, m)
+	if deployment := fake.deployment(state.ID); deployment != nil {
+		t.Errorf("vra_deployment %s still exists after destroy", state.ID)
+	}
+	if state := testResourceRefresh(t, r, state, m); state != nil {
+		t.Errorf("vra_deployment %s still exists after destroy", state.ID)
+	}
+}
+
+func TestResourceDeploymentFakeVRA_CatalogItemVersion(t *testing.T) {
+	fake, m, r := newFakeVRADeployment(t)
+
+	catalogItemID := fake.addCatalogItem("catalog-item", map[string]interface{}{
+		"count": map[string]interface{}{"type": "integer"},
+	}, "1", "2")
+	otherCatalogItemID := fake.addCatalogItem("other-catalog-item", map[string]interface{}{
+		"count": map[string]interface{}{"type": "integer"},
+	}, "1")
+
+	config := map[string]interface{}{
+		"name":                 "deployment",
+		"project_id":           "project-id",
+		"catalog_item_id":      catalogItemID,
+		"catalog_item_version": "1",
+		"inputs": map[string]interface{}{
+			"count": "1",
+		},
+	}
+	state := testResourceApply(t, r, nil, config, m)
+
+	id := state.ID
+	config["catalog_item_version"] = "2"
+	config["inputs"] = map[string]interface{}{"count": "2"}
+	state = testResourceApply(t, r, state, config, m)
+	if state.ID != id {
+		t.Errorf("vra_deployment expected to be updated in place, id changed from %s to %s", id, state.ID)
+	}
+	testCheckResourceAttrs(t, state, map[string]string{
+		"catalog_item_version":                     "2",
+		"inputs.count":                             "2",
+		"last_request.0.action_id":                 "Deployment.Update",
+		"last_request.0.inputs.count":              "2",
+		"last_request.0.inputs.catalogItemVersion": "2",
+	})
+
+	config["catalog_item_id"] = otherCatalogItemID
+	config["catalog_item_version"] = "1"
+	diff, err := r.Diff(context.Background(), testResourceDiffState(t, r, state, config), terraform.NewResourceConfigRaw(config), m)
+	if err != nil {
+		t.Fatalf("error planning the configuration: %s", err)
+	}
+	if attr := diff.Attributes["catalog_item_id"]; attr == nil || !attr.RequiresNew {
+		t.Errorf("vra_deployment expected to be replaced when catalog_item_id changes, actual %#v", attr)
+	}
+
+	// The plan fails when the Update action does not support changing the version, rather than replacing the deployment
+	fake.deploymentActions["Deployment.Update"] = map[string]interface{}{}
+	config["catalog_item_id"] = catalogItemID
+	config["catalog_item_version"] = "1"
+	_, err = r.Diff(context.Background(), testResourceDiffState(t, r, state, config), terraform.NewResourceConfigRaw(config), m)
+	var pathErr cty.PathError
+	if !errors.As(err, &pathErr) || !pathErr.Path.Equals(cty.GetAttrPath("catalog_item_version")) {
+		t.Fatalf("vra_deployment expected an error on catalog_item_version when the Update action does not support the version, actual %#v", err)
+	}
+	if !strings.Contains(err.Error(), `has no "catalogItemVersion" input`) {
+		t.Errorf("vra_deployment expected the missing input in the error, actual %s", err)
+	}
+
+	testResourceDestroy(t, r, state, m)
+}
+
+func TestResourceDeploymentFakeVRA_Blueprint(t *testing.T) {
+	fake := newFakeVRA(t)
+	m := fake.client(t)
+	r := resourceDeployment()
+
+	blueprintID := fake.addBlueprint("blueprint", map[string]interface{}{
+		"count": map[string]interface{}{"type": "integer"},
+	}, "1")
+
+	state := testResourceApply(t, r, nil, map[string]interface{}{
+		"name":              "deployment",
+		"project_id":        "project-id",
+		"blueprint_id":      blueprintID,
+		"blueprint_version": "1",
+		"inputs": map[string]interface{}{
+			"count": "2",
+		},
+	}, m)
+	testCheckResourceAttrs(t, state, map[string]string{
+		"blueprint_id":      blueprintID,
+		"blueprint_version": "1",
+		"status":            models.DeploymentStatusCREATESUCCESSFUL,
+		"inputs.count":      "2",
+	})
+
+	deployment := fake.deployment(state.ID)
+	if count := deployment.Inputs.(map[string]interface{})["count"]; count != float64(2) {
+		t.Errorf("resourceDeploymentCreate expected the count input to be requested as a number, actual %#v", count)
+	}
+
+	testResourceDestroy(t, r, state, m)
+}
+
+func TestResourceDeploymentFakeVRA_BlueprintWithoutInputsSchema(t *testing.T) {
+	fake := newFakeVRA(t)
+	m := fake.client(t)
+
+	blueprintID := fake.addBlueprint("blueprint", nil)
+	fake.blueprints[blueprintID].latest = map[string]interface{}{}
+
+	inputsSchema, err := getBlueprintSchema(m.apiClient, blueprintID, "")
+	if err != nil {
+		t.Fatalf("getBlueprintSchema returned error %s", err)
+	}
+	if inputsSchema == nil || len(inputsSchema) != 0 {
+		t.Errorf("getBlueprintSchema expected an empty schema for a blueprint without inputs schema, actual %v", inputsSchema)
+	}
+}
+
+func TestResourceDeploymentFakeVRA_Failure(t *testing.T) {
+	fake, m, r := newFakeVRADeployment(t)
+	fake.deploymentFailure = "no placement found"
+
+	blueprintID := fake.addBlueprint("blueprint", map[string]interface{}{})
+	fake.setBlueprintResources(blueprintID, "", map[string]string{"Cloud_Machine_1": "Cloud.vSphere.Machine"})
+
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"name":         "deployment",
+		"project_id":   "project-id",
+		"blueprint_id": blueprintID,
+	})
+	diff, err := r.Diff(context.Background(), nil, config, m)
+	if err != nil {
+		t.Fatalf("error planning the configuration: %s", err)
+	}
+
+	state, diags := r.Apply(context.Background(), nil, diff, m)
+	if !diags.HasError() {
+		t.Fatalf("resourceDeploymentCreate expected an error for a failed deployment")
+	}
+	if !strings.Contains(diags[len(diags)-1].Summary, fake.deploymentFailure) {
+		t.Errorf("resourceDeploymentCreate expected the failure message %q in the diagnostics, actual %v", fake.deploymentFailure, diags)
+	}
+	for _, expected := range []string{
+		"Create requested",
+		"Create failed [Cloud_Machine_1 (Cloud.vSphere.Machine)]: no placement found",
+		"Failed resources:\n  Cloud_Machine_1 (Cloud.vSphere.Machine): no placement found",
+	} {
+		if !strings.Contains(diags[len(diags)-1].Detail, expected) {
+			t.Errorf("resourceDeploymentCreate expected %q in the details of the diagnostics, actual %q", expected, diags[len(diags)-1].Detail)
+		}
+	}
+	if state == nil || state.Attributes["status"] != models.DeploymentStatusCREATEFAILED {
+		t.Fatalf("resourceDeploymentCreate expected the failed deployment to be kept in the state, actual %v", state)
+	}
+	testCheckResourceAttrs(t, state, map[string]string{
+		"last_request_events.#":               "2",
+		"last_request_events.0.name":          "Create requested",
+		"last_request_events.1.name":          "Create failed",
+		"last_request_events.1.details":       fake.deploymentFailure,
+		"last_request_events.1.resource_name": "Cloud_Machine_1",
+		"last_request_events.1.resource_type": "Cloud.vSphere.Machine",
+	})
+
+	// The details do not fail for the events and resources without the optional fields
+	fake.mu.Lock()
+	deployment := fake.deployments[state.ID]
+	fake.requestEvents[deployment.LastRequest.ID.String()] = append(fake.requestEvents[deployment.LastRequest.ID.String()], &models.Event{Details: "sparse event"})
+	deployment.Resources = append(deployment.Resources, &models.DeploymentResource{
+		ID:             strfmt.UUID("00000000-0000-4000-8000-999999999999"),
+		CurrentRequest: deployment.LastRequest,
+	})
+	fake.mu.Unlock()
+	details, err := getDeploymentRequestFailureDetails(m.apiClient, state.ID)
+	if err != nil {
+		t.Fatalf("getDeploymentRequestFailureDetails returned error %s", err)
+	}
+	for _, expected := range []string{
+		"\n  : sparse event",
+		"\n  00000000-0000-4000-8000-999999999999: no placement found",
+	} {
+		if !strings.Contains(details, expected) {
+			t.Errorf("getDeploymentRequestFailureDetails expected %q in the details, actual %q", expected, details)
+		}
+	}
+	state = testResourceRefresh(t, r, state, m)
+	testCheckResourceAttrs(t, state, map[string]string{
+		"last_request_events.#":         "3",
+		"last_request_events.2.details": "sparse event",
+		"last_request_events.2.name":    "",
+	})
+
+	// The refresh does not fail when the events cannot be retrieved
+	fake.requestEventsUnavailable = true
+	state = testResourceRefresh(t, r, state, m)
+	testCheckResourceAttrs(t, state, map[string]string{
+		"last_request_events.#": "0",
+		"status":                models.DeploymentStatusCREATEFAILED,
+	})
+}
+
+func TestResourceDeploymentFakeVRA_Approval(t *testing.T) {
+	fake := newFakeVRA(t)
+	fake.approvers = []string{"approver@example.com", "approvers-group"}
+	m := fake.client(t)
+	r := resourceDeployment()
+
+	apply := func(config map[string]interface{}) (*terraform.InstanceState, diag.Diagnostics) {
+		diff, err := r.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(config), m)
+		if err != nil {
+			t.Fatalf("error planning the configuration: %s", err)
+		}
+		return r.Apply(context.Background(), nil, diff, m)
+	}
+
+	// A request pending approval fails right away when not waited for
+	_, diags := apply(map[string]interface{}{
+		"name":                "not-waited-deployment",
+		"project_id":          "project-id",
+		"on_approval_pending": "fail",
+	})
+	if !diags.HasError() {
+		t.Fatalf("resourceDeploymentCreate expected an error for a request pending approval")
+	}
+	for _, expected := range []string{"is pending approval", "by approver@example.com, approvers-group"} {
+		if !strings.Contains(diags[len(diags)-1].Summary, expected) {
+			t.Errorf("resourceDeploymentCreate expected %q in the diagnostics, actual %v", expected, diags)
+		}
+	}
+
+	// A request pending approval is waited for until it is approved
+	state, diags := apply(map[string]interface{}{
+		"name":       "approved-deployment",
+		"project_id": "project-id",
+	})
+	if diags.HasError() {
+		t.Fatalf("resourceDeploymentCreate returned an error for an approved request: %v", diags)
+	}
+	testCheckResourceAttrs(t, state, map[string]string{
+		"status":                 models.DeploymentStatusCREATESUCCESSFUL,
+		"approval.#":             "1",
+		"approval.0.status":      "APPROVED",
+		"approval.0.action_by":   fakeVRAUser,
+		"approval.0.approvers.#": "2",
+		"approval.0.approvers.0": "approver@example.com",
+	})
+
+	// A rejected request fails with the comment of the approver
+	fake.approvalRejection = "not in this quarter"
+	_, diags = apply(map[string]interface{}{
+		"name":       "rejected-deployment",
+		"project_id": "project-id",
+	})
+	if !diags.HasError() {
+		t.Fatalf("resourceDeploymentCreate expected an error for a rejected request")
+	}
+	for _, expected := range []string{"was rejected by " + fakeVRAUser, fake.approvalRejection} {
+		if !strings.Contains(diags[len(diags)-1].Summary, expected) {
+			t.Errorf("resourceDeploymentCreate expected %q in the diagnostics, actual %v", expected, diags)
+		}
+	}
+
+	// A day-2 action pending approval fails right away when not waited for
+	approvers := fake.approvers
+	fake.approvalRejection = ""
+	fake.approvers = nil
+	config := map[string]interface{}{
+		"name":                "updated-deployment",
+		"project_id":          "project-id",
+		"on_approval_pending": "fail",
+	}
+	state = testResourceApply(t, r, nil, config, m)
+	fake.approvers = approvers
+	config["owner"] = "new-owner@example.com"
+	diff, err := r.Diff(context.Background(), testResourceDiffState(t, r, state, config), terraform.NewResourceConfigRaw(config), m)
+	if err != nil {
+		t.Fatalf("error planning the configuration: %s", err)
+	}
+	if _, diags := r.Apply(context.Background(), state, diff, m); !diags.HasError() || !strings.Contains(diags[len(diags)-1].Summary, "is pending approval") {
+		t.Errorf("resourceDeploymentUpdate expected an error for a day-2 action pending approval, actual %v", diags)
+	}
+
+	// A day-2 action pending approval is waited for by default
+	delete(config, "on_approval_pending")
+	config["name"] = "waited-updated-deployment"
+	delete(config, "owner")
+	fake.approvers = nil
+	state = testResourceApply(t, r, nil, config, m)
+	fake.approvers = approvers
+	config["owner"] = "new-owner@example.com"
+	state = testResourceApply(t, r, state, config, m)
+	testCheckResourceAttrs(t, state, map[string]string{
+		"owner":             "new-owner@example.com",
+		"approval.0.status": "APPROVED",
+	})
+}
+
+func TestResourceDeploymentFakeVRA_Import(t *testing.T) {
+	fake, m, r := newFakeVRADeployment(t)
+	// The last successful request of the updated deployment is on the second page of its requests
+	fake.pageSize = 1
+
+	projectID := fake.addProject("project")
+	quotedProjectID := fake.addProject("o'project")
+	inputsSchema := map[string]interface{}{
+		"count":  map[string]interface{}{"type": "integer"},
+		"image":  map[string]interface{}{"type": "string"},
+		"tags":   map[string]interface{}{"type": "array"},
+		"flavor": map[string]interface{}{"type": "string", "default": "small"},
+	}
+	catalogItemID := fake.addCatalogItem("catalog-item", inputsSchema, "1", "2")
+	blueprintID := fake.addBlueprint("blueprint", inputsSchema, "1", "2")
+
+	var tests = []struct {
+		config   map[string]interface{}
+		updated  map[string]interface{}
+		importID func(id string) string
+		expected map[string]string
+	}{
+		{
+			map[string]interface{}{
+				"name":                 "catalog-item-deployment",
+				"project_id":           projectID,
+				"catalog_item_id":      catalogItemID,
+				"catalog_item_version": "1",
+				"inputs": map[string]interface{}{
+					"count": "2",
+					"image": "ubuntu",
+					"tags":  `["a","b"]`,
+				},
+			},
+			nil,
+			func(string) string { return "project/catalog-item-deployment" },
+			map[string]string{
+				"catalog_item_id":      catalogItemID,
+				"catalog_item_version": "1",
+				"inputs.%":             "3",
+				"inputs.count":         "2",
+				"inputs.image":         "ubuntu",
+				"inputs.tags":          `["a","b"]`,
+			},
+		},
+		{
+			map[string]interface{}{
+				"name":              "blueprint-deployment",
+				"project_id":        projectID,
+				"blueprint_id":      blueprintID,
+				"blueprint_version": "2",
+				"inputs": map[string]interface{}{
+					"image": "centos",
+				},
+			},
+			nil,
+			func(id string) string { return id },
+			map[string]string{
+				"blueprint_id":      blueprintID,
+				"blueprint_version": "2",
+				"inputs.%":          "1",
+				"inputs.image":      "centos",
+			},
+		},
+		{
+			map[string]interface{}{
+				"name":              "inputs-json-deployment",
+				"project_id":        projectID,
+				"blueprint_id":      blueprintID,
+				"blueprint_version": "1",
+				"inputs_json":       `{"count":2,"tags":["a","b"]}`,
+			},
+			nil,
+			func(id string) string { return "inputs_json:" + id },
+			map[string]string{
+				"blueprint_id":      blueprintID,
+				"blueprint_version": "1",
+				"inputs.%":          "",
+				"inputs_json":       `{"count":2,"tags":["a","b"]}`,
+			},
+		},
+		{
+			map[string]interface{}{
+				"name":                 "updated-deployment",
+				"project_id":           quotedProjectID,
+				"catalog_item_id":      catalogItemID,
+				"catalog_item_version": "1",
+				"inputs": map[string]interface{}{
+					"count": "1",
+				},
+			},
+			// The inputs and the version of the catalog item of the Update action are imported
+			map[string]interface{}{
+				"name":                 "updated-deployment",
+				"project_id":           quotedProjectID,
+				"catalog_item_id":      catalogItemID,
+				"catalog_item_version": "2",
+				"inputs": map[string]interface{}{
+					"count": "3",
+					"image": "debian",
+				},
+			},
+			func(string) string { return "o'project/updated-deployment" },
+			map[string]string{
+				"catalog_item_id":      catalogItemID,
+				"catalog_item_version": "2",
+				"inputs.%":             "2",
+				"inputs.count":         "3",
+				"inputs.image":         "debian",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		state := testResourceApply(t, r, nil, tt.config, m)
+		config := tt.config
+		if tt.updated != nil {
+			state = testResourceApply(t, r, state, tt.updated, m)
+			config = tt.updated
+		}
+
+		imported := testResourceImport(t, r, tt.importID(state.ID), m)
+		if imported.ID != state.ID {
+			t.Fatalf("vra_deployment %s expected to be imported, actual %s", state.ID, imported.ID)
+		}
+		testCheckResourceAttrs(t, imported, tt.expected)
+
+		// The imported deployment has no changes to plan
+		diff, err := r.Diff(context.Background(), testResourceDiffState(t, r, imported, config), terraform.NewResourceConfigRaw(config), m)
+		if err != nil {
+			t.Fatalf("error planning the imported vra_deployment: %s", err)
+		}
+		if diff != nil && !diff.Empty() {
+			t.Errorf("imported vra_deployment %s expected no changes to plan, actual %v", state.ID, diff.Attributes)
+		}
+	}
+
+	for _, id := range []string{"unknown-project/catalog-item-deployment", "project/unknown-deployment", "project/"} {
+		d := r.Data(nil)
+		d.SetId(id)
+		if _, err := r.Importer.StateContext(context.Background(), d, m); err == nil {
+			t.Errorf("vra_deployment import of %s expected an error", id)
+		}
+	}
+}
+
+func TestResourceDeploymentFakeVRA_Delete(t *testing.T) {
+	fake, m, r := newFakeVRADeployment(t)
+
+	config := map[string]interface{}{
+		"name":       "deployment",
+		"project_id": "project-id",
+	}
+
+	// A deployment without deletion policy, such as in a state written before the policy existed, has no changes
+	state := testResourceApply(t, r, nil, config, m)
+	if _, ok := state.Attributes["deletion_policy"]; ok {
+		t.Errorf("vra_deployment expected no deletion_policy when it is not configured, actual %s", state.Attributes["deletion_policy"])
+	}
+	diff, err := r.Diff(context.Background(), testResourceDiffState(t, r, state, config), terraform.NewResourceConfigRaw(config), m)
+	if err != nil {
+		t.Fatalf("error planning the configuration: %s", err)
+	}
+	if !diff.Empty() {
+		t.Errorf("vra_deployment expected no changes without deletion policy, actual %v", diff.Attributes)
+	}
+
+	// An abandoned deployment is only removed from the state
+	config["deletion_policy"] = "abandon"
+	state = testResourceApply(t, r, state, config, m)
+	testResourceDestroy(t, r, state, m)
+	if deployment := fake.deployment(state.ID); deployment == nil {
+		t.Errorf("vra_deployment %s expected to be kept after destroy with the abandon deletion policy", state.ID)
+	}
+
+	// A failed delete is not retried by default
+	delete(config, "deletion_policy")
+	config["name"] = "failed-deployment"
+	state = testResourceApply(t, r, nil, config, m)
+	fake.deleteFailures = 1
+	if _, diags := r.Apply(context.Background(), state, &terraform.InstanceDiff{Destroy: true}, m); !diags.HasError() {
+		t.Errorf("resourceDeploymentDelete expected an error for a failed delete")
+	}
+	if deployment := fake.deployment(state.ID); deployment == nil || deployment.Status != models.DeploymentStatusDELETEFAILED {
+		t.Errorf("vra_deployment %s expected to have failed to be deleted, actual %v", state.ID, deployment)
+	}
+
+	// A failed delete is retried ignoring the delete failures, and the retry succeeds
+	config["name"] = "retried-deployment"
+	config["delete_retry"] = 2
+	state = testResourceApply(t, r, nil, config, m)
+	fake.deleteFailures = 1
+	testResourceDestroy(t, r, state, m)
+	if deployment := fake.deployment(state.ID); deployment != nil {
+		t.Errorf("vra_deployment %s expected to be deleted by the retry, actual %v", state.ID, deployment)
+	}
+	if fake.deleteFailures != 0 {
+		t.Errorf("resourceDeploymentDelete expected the first delete to fail, actual %d failures left", fake.deleteFailures)
+	}
+
+	// A stuck deployment is deleted by canceling its request in progress
+	delete(config, "delete_retry")
+	config["name"] = "stuck-deployment"
+	config["force_delete"] = true
+	state = testResourceApply(t, r, nil, config, m)
+	requestID := fake.deployment(state.ID).LastRequest.ID.String()
+	fake.deleteFailures = 1
+	fake.updateDeployment(state.ID, func(deployment *models.Deployment) {
+		deployment.Status = models.DeploymentStatusUPDATEINPROGRESS
+		deployment.LastRequest.Status = models.RequestStatusINPROGRESS
+		deployment.LastRequest.Cancelable = true
+	})
+	fake.mu.Lock()
+	request := fake.requests[requestID]
+	fake.mu.Unlock()
+	testResourceDestroy(t, r, state, m)
+	if deployment := fake.deployment(state.ID); deployment != nil {
+		t.Errorf("vra_deployment %s expected to be force deleted, actual %v", state.ID, deployment)
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if request.Status != models.RequestStatusABORTED {
+		t.Errorf("resourceDeploymentDelete expected the request in progress to be canceled, actual %s", request.Status)
+	}
+}
+
+func TestResourcePolicyLeaseFakeVRA(t *testing.T) {
+	fake := newFakeVRA(t)
+	m := fake.client(t)
+	r := resourcePolicyLease()
+
+	config := map[string]interface{}{
+		"name":                 "lease-policy",
+		"enforcement_type":     "HARD",
+		"lease_term_max":       10,
+		"lease_total_term_max": 100,
+		"project_id":           "project-id",
+	}
+
+	state := testResourceApply(t, r, nil, config, m)
+	testCheckResourceAttrs(t, state, map[string]string{
+		"name":                 "lease-policy",
+		"enforcement_type":     "HARD",
+		"lease_term_max":       "10",
+		"lease_total_term_max": "100",
+		"org_id":               fakeVRAOrgID,
+	})
+
+	id := state.ID
+	config["lease_term_max"] = 20
+	state = testResourceApply(t, r, state, config, m)
+	if state.ID != id {
+		t.Errorf("vra_policy_lease expected to be updated in place, id changed from %s to %s", id, state.ID)
+	}
+	testCheckResourceAttrs(t, state, map[string]string{"lease_term_max": "20"})
+
+	imported := testResourceImport(t, r, state.ID, m)
+	testCheckResourceAttrs(t, imported, map[string]string{
+		"name":                 "lease-policy",
+		"lease_term_max":       "20",
+		"lease_total_term_max": "100",
+		"project_id":           "project-id",
+	})
+
+	testResourceDestroy(t, r, state, m)
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if _, ok := fake.policies[id]; ok {
+		t.Errorf("vra_policy_lease %s still exists after destroy", id)
+	}
+}
+
+func TestResourcePolicyApprovalFakeVRA(t *testing.T) {
+	fake := newFakeVRA(t)
+	m := fake.client(t)
+	r := resourcePolicyApproval()
+
+	state := testResourceApply(t, r, nil, map[string]interface{}{
+		"name":                   "approval-policy",
+		"actions":                []interface{}{"Deployment.Delete"},
+		"approval_level":         1,
+		"approval_mode":          "ANY_OF",
+		"approval_type":          "USER",
+		"approvers":              []interface{}{"USER:approver@example.com"},
+		"auto_approval_decision": "APPROVE",
+		"auto_approval_expiry":   7,
+		"enforcement_type":       "HARD",
+	}, m)
+
+	imported := testResourceImport(t, r, state.ID, m)
+	testCheckResourceAttrs(t, imported, map[string]string{
+		"name":                   "approval-policy",
+		"actions.#":              "1",
+		"approval_mode":          "ANY_OF",
+		"approvers.#":            "1",
+		"auto_approval_decision": "APPROVE",
+		"auto_approval_expiry":   "7",
+	})
+
+	testResourceDestroy(t, r, state, m)
+}
+
+func TestResourceDeploymentFakeVRA_Simulate(t *testing.T) {
+	fake, m, r := newFakeVRADeployment(t)
+
+	blueprintID := fake.addBlueprint("blueprint", map[string]interface{}{
+		"count": map[string]interface{}{"type": "integer"},
+	}, "1", "2")
+	fake.setBlueprintResources(blueprintID, "1", map[string]string{
+		"Cloud_Machine_1": "Cloud.Machine",
+		"Cloud_Volume_1":  "Cloud.Volume",
+	})
+	fake.setBlueprintResources(blueprintID, "2", map[string]string{
+		"Cloud_Machine_1": "Cloud.Machine",
+	})
+
+	config := map[string]interface{}{
+		"name":              "deployment",
+		"project_id":        "project-id",
+		"blueprint_id":      blueprintID,
+		"blueprint_version": "1",
+		"inputs": map[string]interface{}{
+			"count": "1",
+		},
+		"simulate": true,
+	}
+
+	diff, err := r.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(config), m)
+	if err != nil {
+		t.Fatalf("error planning the configuration: %s", err)
+	}
+	for key, expected := range map[string]string{
+		"planned_changes.#":               "2",
+		"planned_changes.0.change":        "CREATE",
+		"planned_changes.0.resource_name": "Cloud_Machine_1",
+		"planned_changes.0.resource_type": "Cloud.Machine",
+		"planned_changes.1.change":        "CREATE",
+		"planned_changes.1.resource_name": "Cloud_Volume_1",
+	} {
+		if attr, ok := diff.Attributes[key]; !ok || attr.New != expected {
+			t.Errorf("resourceDeploymentCustomizeDiff expected %s %q, actual %v", key, expected, attr)
+		}
+	}
+
+	// The planned changes are only kept in the plan, they are applied by then
+	state := testResourceApply(t, r, nil, config, m)
+	testCheckResourceAttrs(t, state, map[string]string{
+		"planned_changes.#": "0",
+		"resources.#":       "2",
+	})
+
+	config["blueprint_version"] = "2"
+	diff, err = r.Diff(context.Background(), testResourceDiffState(t, r, state, config), terraform.NewResourceConfigRaw(config), m)
+	if err != nil {
+		t.Fatalf("error planning the configuration: %s", err)
+	}
+	for key, expected := range map[string]string{
+		"planned_changes.0.change": "UPDATE",
+		"planned_changes.1.change": "DELETE",
+	} {
+		if attr, ok := diff.Attributes[key]; !ok || attr.New != expected {
+			t.Errorf("resourceDeploymentCustomizeDiff expected %s %q, actual %v", key, expected, attr)
+		}
+	}
+
+	state = testResourceApply(t, r, state, config, m)
+	testCheckResourceAttrs(t, state, map[string]string{
+		"blueprint_version": "2",
+		"planned_changes.#": "0",
+		"resources.#":       "1",
+	})
+	diff, err = r.Diff(context.Background(), testResourceDiffState(t, r, state, config), terraform.NewResourceConfigRaw(config), m)
+	if err != nil {
+		t.Fatalf("error planning the configuration: %s", err)
+	}
+	if !diff.Empty() {
+		t.Errorf("vra_deployment expected no changes once applied, actual %v", diff.Attributes)
+	}
+
+	fake.mu.Lock()
+	if len(fake.blueprintRequests) != 0 {
+		t.Errorf("resourceDeploymentCustomizeDiff expected the plan requests to be deleted, actual %d left", len(fake.blueprintRequests))
+	}
+	fake.mu.Unlock()
+
+	testResourceDestroy(t, r, state, m)
+
+	// A deployment without simulate has no planned changes, and no changes to plan
+	delete(config, "simulate")
+	config["name"] = "not-simulated-deployment"
+	state = testResourceApply(t, r, nil, config, m)
+	testCheckResourceAttrs(t, state, map[string]string{
+		"planned_changes.#": "0",
+	})
+	diff, err = r.Diff(context.Background(), testResourceDiffState(t, r, state, config), terraform.NewResourceConfigRaw(config), m)
+	if err != nil {
+		t.Fatalf("error planning the configuration: %s", err)
+	}
+	if !diff.Empty() {
+		t.Errorf("vra_deployment expected no changes without simulate, actual %v", diff.Attributes)
+	}
+}
+
+func TestResourceDeploymentActionFakeVRA(t *testing.T) {
+	fake, m, deploymentResource := newFakeVRADeployment(t)
+
+	deployment := testResourceApply(t, deploymentResource, nil, map[string]interface{}{
+		"name":       "deployment",
+		"project_id": "project-id",
+	}, m)
+
+	r := resourceDeploymentAction()
+	config := map[string]interface{}{
+		"deployment_id": deployment.ID,
+		"action_name":   "ChangeOwner",
+		"inputs": map[string]interface{}{
+			"New Owner": "new-owner@example.com",
+		},
+		"triggers": map[string]interface{}{
+			"owner": "new-owner@example.com",
+		},
+	}
+
+	state := testResourceApply(t, r, nil, config, m)
+	testCheckResourceAttrs(t, state, map[string]string{
+		"action_id":                  "Deployment.ChangeOwner",
+		"deployment_id":              deployment.ID,
+		"request.0.action_id":        "Deployment.ChangeOwner",
+		"request.0.status":           models.RequestStatusSUCCESSFUL,
+		"request.0.inputs.New Owner": "new-owner@example.com",
+	})
+	if owner := fake.deployment(deployment.ID).OwnedBy; owner != "new-owner@example.com" {
+		t.Errorf("resourceDeploymentActionCreate expected the owner to be changed, actual %s", owner)
+	}
+
+	// The result of the action is the one of its request, not of a request submitted concurrently on the deployment
+	fake.concurrentRequestFailure = "concurrent request failed"
+	requestID := state.ID
+	config["triggers"] = map[string]interface{}{"owner": "other-owner@example.com"}
+	config["inputs"] = map[string]interface{}{"New Owner": "other-owner@example.com"}
+	state = testResourceApply(t, r, state, config, m)
+	fake.concurrentRequestFailure = ""
+	if state.ID == requestID {
+		t.Errorf("resourceDeploymentAction expected to run the action again when the triggers change")
+	}
+	if owner := fake.deployment(deployment.ID).OwnedBy; owner != "other-owner@example.com" {
+		t.Errorf("resourceDeploymentActionCreate expected the owner to be changed again, actual %s", owner)
+	}
+
+	diff, err := r.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"deployment_id": deployment.ID,
+		"action_name":   "Reboot",
+	}), m)
+	if err != nil {
+		t.Fatalf("error planning the configuration: %s", err)
+	}
+	if _, diags := r.Apply(context.Background(), nil, diff, m); !diags.HasError() {
+		t.Errorf("resourceDeploymentActionCreate expected an error for an action not available on the deployment")
+	}
+
+	testResourceDestroy(t, deploymentResource, deployment, m)
+	if state := testResourceRefresh(t, r, state, m); state != nil {
+		t.Errorf("vra_deployment_action %s still exists after the deployment is destroyed", state.ID)
+	}
+}
+
+func TestResourceDeploymentResourceActionFakeVRA(t *testing.T) {
+	fake, m, deploymentResource := newFakeVRADeployment(t)
+
+	blueprintID := fake.addBlueprint("blueprint", map[string]interface{}{}, "1")
+	fake.setBlueprintResources(blueprintID, "1", map[string]string{
+		"Cloud_vSphere_Machine_1": "Cloud.vSphere.Machine",
+	})
+	deployment := testResourceApply(t, deploymentResource, nil, map[string]interface{}{
+		"name":              "deployment",
+		"project_id":        "project-id",
+		"blueprint_id":      blueprintID,
+		"blueprint_version": "1",
+	}, m)
+
+	r := resourceDeploymentResourceAction()
+	state := testResourceApply(t, r, nil, map[string]interface{}{
+		"deployment_id": deployment.ID,
+		"resource_name": "Cloud_vSphere_Machine_1",
+		"action_name":   "Resize",
+		"inputs": map[string]interface{}{
+			"cpuCount":      "4",
+			"totalMemoryMB": "8192",
+		},
+	}, m)
+	resource := fake.deployment(deployment.ID).Resources[0]
+	testCheckResourceAttrs(t, state, map[string]string{
+		"action_id":        "Cloud.vSphere.Machine.Resize",
+		"deployment_id":    deployment.ID,
+		"resource_id":      resource.ID.String(),
+		"request.0.status": models.RequestStatusSUCCESSFUL,
+	})
+	if cpuCount := resource.Properties.(map[string]interface{})["cpuCount"]; cpuCount != float64(4) {
+		t.Errorf("resourceDeploymentResourceActionCreate expected the cpuCount input to be requested as a number, actual %#v", cpuCount)
+	}
+
+	var tests = []struct {
+		resourceName string
+		actionName   string
+		err          string
+	}{
+		{"Cloud_vSphere_Machine_2", "PowerOff", "resource Cloud_vSphere_Machine_2 is not found"},
+		{"Cloud_vSphere_Machine_1", "Reboot", "Reboot action is not found"},
+		{"Cloud_vSphere_Machine_1", "PowerOn", "Cloud.vSphere.Machine.PowerOn action is not valid"},
+	}
+
+	for _, tt := range tests {
+		diff, err := r.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(map[string]interface{}{
+			"deployment_id": deployment.ID,
+			"resource_name": tt.resourceName,
+			"action_name":   tt.actionName,
+		}), m)
+		if err != nil {
+			t.Fatalf("error planning the configuration: %s", err)
+		}
+		_, diags := r.Apply(context.Background(), nil, diff, m)
+		if !diags.HasError() || !strings.Contains(diags[0].Summary, tt.err) {
+			t.Errorf("resourceDeploymentResourceActionCreate for %s on %s expected error %q, actual %v", tt.actionName, tt.resourceName, tt.err, diags)
+		}
+	}
+
+	testResourceApply(t, r, nil, map[string]interface{}{
+		"deployment_id": deployment.ID,
+		"resource_name": "Cloud_vSphere_Machine_1",
+		"action_name":   "PowerOff",
+	}, m)
+	if powerState := resource.Properties.(map[string]interface{})["powerState"]; powerState != "OFF" {
+		t.Errorf("resourceDeploymentResourceActionCreate expected the resource to be powered off, actual %v", powerState)
+	}
+}
+
+func TestResourceDeploymentFakeVRA_Lease(t *testing.T) {
+	fake, m, r := newFakeVRADeployment(t)
+
+	// The lease policy of the project is on the second page of the lease policies
+	fake.pageSize = 1
+	testResourceApply(t, resourcePolicyLease(), nil, map[string]interface{}{
+		"name":                 "other-lease-policy",
+		"enforcement_type":     "HARD",
+		"lease_term_max":       1,
+		"lease_total_term_max": 1,
+		"project_id":           "other-project-id",
+	}, m)
+	testResourceApply(t, resourcePolicyLease(), nil, map[string]interface{}{
+		"name":                 "lease-policy",
+		"enforcement_type":     "HARD",
+		"lease_term_max":       30,
+		"lease_total_term_max": 100,
+		"project_id":           "project-id",
+	}, m)
+
+	config := map[string]interface{}{
+		"name":       "deployment",
+		"project_id": "project-id",
+		"lease_days": 7,
+	}
+	state := testResourceApply(t, r, nil, config, m)
+	leaseExpireAt := time.Time(fake.deployment(state.ID).LeaseExpireAt)
+	if expected := time.Now().AddDate(0, 0, 7); leaseExpireAt.Before(expected.Add(-time.Hour)) || leaseExpireAt.After(expected) {
+		t.Errorf("resourceDeploymentCreate expected the lease to expire in 7 days, actual %s", leaseExpireAt)
+	}
+
+	id := state.ID
+	expireAt := time.Now().UTC().AddDate(0, 0, 20).Truncate(time.Second)
+	config = map[string]interface{}{
+		"name":            "deployment",
+		"project_id":      "project-id",
+		"lease_expire_at": expireAt.Format(time.RFC3339),
+	}
+	state = testResourceApply(t, r, state, config, m)
+	if state.ID != id {
+		t.Errorf("vra_deployment expected to be updated in place, id changed from %s to %s", id, state.ID)
+	}
+	if leaseExpireAt := time.Time(fake.deployment(state.ID).LeaseExpireAt); !leaseExpireAt.Equal(expireAt) {
+		t.Errorf("resourceDeploymentUpdate expected the lease to expire at %s, actual %s", expireAt, leaseExpireAt)
+	}
+
+	diff, err := r.Diff(context.Background(), testResourceDiffState(t, r, state, config), terraform.NewResourceConfigRaw(config), m)
+	if err != nil {
+		t.Fatalf("error planning the configuration: %s", err)
+	}
+	if diff != nil && diff.Attributes["lease_expire_at"] != nil {
+		t.Errorf("vra_deployment expected no changes for the same lease expiration date, actual %#v", diff.Attributes["lease_expire_at"])
+	}
+
+	config["lease_expire_at"] = time.Now().UTC().AddDate(0, 0, 60).Format(time.RFC3339)
+	diff, err = r.Diff(context.Background(), testResourceDiffState(t, r, state, config), terraform.NewResourceConfigRaw(config), m)
+	if err != nil {
+		t.Fatalf("error planning the configuration: %s", err)
+	}
+	_, diags := r.Apply(context.Background(), state, diff, m)
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, "maximum lease of 30 days") {
+		t.Errorf("resourceDeploymentUpdate expected an error for a lease exceeding the lease policy, actual %v", diags)
+	}
+	if leaseExpireAt := time.Time(fake.deployment(state.ID).LeaseExpireAt); !leaseExpireAt.Equal(expireAt) {
+		t.Errorf("resourceDeploymentUpdate expected the lease not to be changed, actual %s", leaseExpireAt)
+	}
+
+	testResourceDestroy(t, r, state, m)
+}
+
+func TestResourceDeploymentFakeVRA_InputsJSON(t *testing.T) {
+	fake, m, r := newFakeVRADeployment(t)
+
+	blueprintID := fake.addBlueprint("blueprint", map[string]interface{}{
+		"count":  map[string]interface{}{"type": "integer"},
+		"name":   map[string]interface{}{"type": "string"},
+		"tags":   map[string]interface{}{"type": "array"},
+		"config": map[string]interface{}{"type": "object"},
+		"flavor": map[string]interface{}{"type": "string", "default": "small"},
+	}, "1")
+
+	config := map[string]interface{}{
+		"name":              "deployment",
+		"project_id":        "project-id",
+		"blueprint_id":      blueprintID,
+		"blueprint_version": "1",
+		"inputs_json":       `{"count": 2, "name": 5, "tags": ["a", "b"], "config": {"b": [1, 2], "a": "x"}}`,
+	}
+	state := testResourceApply(t, r, nil, config, m)
+	testCheckResourceAttrs(t, state, map[string]string{
+		"inputs_including_defaults.count":  "2",
+		"inputs_including_defaults.name":   "5",
+		"inputs_including_defaults.tags":   `["a","b"]`,
+		"inputs_including_defaults.config": `{"a":"x","b":[1,2]}`,
+	})
+
+	inputs := fake.deployment(state.ID).Inputs.(map[string]interface{})
+	if name := inputs["name"]; name != "5" {
+		t.Errorf("resourceDeploymentCreate expected the name input to be requested as a string, actual %#v", name)
+	}
+	if tags := inputs["tags"]; !reflect.DeepEqual(tags, []interface{}{"a", "b"}) {
+		t.Errorf("resourceDeploymentCreate expected the tags input to be requested as an array, actual %#v", tags)
+	}
+
+	state = testResourceRefresh(t, r, state, m)
+	config["inputs_json"] = `{"config": {"a": "x", "b": [1, 2]}, "tags": ["a", "b"], "name": 5, "count": 2}`
+	diff, err := r.Diff(context.Background(), testResourceDiffState(t, r, state, config), terraform.NewResourceConfigRaw(config), m)
+	if err != nil {
+		t.Fatalf("error planning the configuration: %s", err)
+	}
+	if diff != nil && diff.Attributes["inputs_json"] != nil {
+		t.Errorf("vra_deployment expected no changes for the same inputs in another order, actual %#v", diff.Attributes["inputs_json"])
+	}
+
+	id := state.ID
+	config["inputs_json"] = `{"count": 3, "name": 5, "tags": ["a", "b"], "config": {"b": [1, 2], "a": "x"}}`
+	state = testResourceApply(t, r, state, config, m)
+	if state.ID != id {
+		t.Errorf("vra_deployment expected to be updated in place, id changed from %s to %s", id, state.ID)
+	}
+	testCheckResourceAttrs(t, state, map[string]string{
+		"inputs_including_defaults.count": "3",
+		"last_request.0.action_id":        "Deployment.Update",
+	})
+
+	testResourceDestroy(t, r, state, m)
+}
+
+func TestResourceDeploymentFakeVRA_Outputs(t *testing.T) {
+	fake, m, r := newFakeVRADeployment(t)
+
+	blueprintID := fake.addBlueprint("blueprint", map[string]interface{}{})
+	fake.setBlueprintOutputs(blueprintID, map[string]interface{}{
+		"url":       "https://app.example.com",
+		"port":      8443,
+		"ha":        true,
+		"addresses": []interface{}{"10.0.0.1", "10.0.0.2"},
+		"admin":     map[string]interface{}{"user": "admin", "groups": []interface{}{"ops"}},
+	})
+
+	state := testResourceApply(t, r, nil, map[string]interface{}{
+		"name":         "deployment",
+		"project_id":   "project-id",
+		"blueprint_id": blueprintID,
+	}, m)
+	testCheckResourceAttrs(t, state, map[string]string{
+		"outputs.%":         "5",
+		"outputs.url":       `"https://app.example.com"`,
+		"outputs.port":      "8443",
+		"outputs.ha":        "true",
+		"outputs.addresses": `["10.0.0.1","10.0.0.2"]`,
+		"outputs.admin":     `{"groups":["ops"],"user":"admin"}`,
+		"name":              "deployment",
+	})
+
+	// The deployment and its outputs are read with the same request, whose errors are those of the SDK
+	delete(fake.deployments, state.ID)
+	if _, _, err := getDeploymentWithOutputs(m.apiClient, state.ID, nil); !errors.As(err, new(*deployments.GetDeploymentByIDV3UsingGETNotFound)) {
+		t.Errorf("getDeploymentWithOutputs expected a not found error for a deleted deployment, actual %#v", err)
+	}
+	if state := testResourceRefresh(t, r, state, m); state != nil {
+		t.Errorf("resourceDeploymentRead expected the deleted deployment to be removed from the state, actual %v", state)
+	}
+}
+
+func TestResourceDeploymentFakeVRA_Drift(t *testing.T) {
+	fake, m, r := newFakeVRADeployment(t)
+
+	blueprintID := fake.addBlueprint("blueprint", map[string]interface{}{
+		"count":  map[string]interface{}{"type": "integer"},
+		"size":   map[string]interface{}{"type": "number"},
+		"image":  map[string]interface{}{"type": "string"},
+		"flavor": map[string]interface{}{"type": "string", "default": "small"},
+	}, "1", "2")
+
+	config := map[string]interface{}{
+		"name":              "deployment",
+		"project_id":        "project-id",
+		"blueprint_id":      blueprintID,
+		"blueprint_version": "1",
+		"inputs": map[string]interface{}{
+			"count": "2",
+			"size":  "1.50",
+			"image": "ubuntu",
+		},
+	}
+	state := testResourceApply(t, r, nil, config, m)
+
+	// The equivalent values of the deployment are not drift
+	state = testResourceRefresh(t, r, state, m)
+	testCheckResourceAttrs(t, state, map[string]string{
+		"inputs.%":     "3",
+		"inputs.count": "2",
+		"inputs.size":  "1.50",
+		"inputs.image": "ubuntu",
+	})
+
+	fake.updateDeployment(state.ID, func(deployment *models.Deployment) {
+		inputs := deployment.Inputs.(map[string]interface{})
+		inputs["count"] = float64(3)
+		inputs["flavor"] = "large"
+		delete(inputs, "image")
+		deployment.BlueprintVersion = "2"
+	})
+
+	state = testResourceRefresh(t, r, state, m)
+	testCheckResourceAttrs(t, state, map[string]string{
+		"blueprint_version": "2",
+		"inputs.%":          "2",
+		"inputs.count":      "3",
+		"inputs.size":       "1.50",
+	})
+
+	diff, err := r.Diff(context.Background(), testResourceDiffState(t, r, state, config), terraform.NewResourceConfigRaw(config), m)
+	if err != nil {
+		t.Fatalf("error planning the configuration: %s", err)
+	}
+	for name, expected := range map[string]string{"blueprint_version": "1", "inputs.count": "2", "inputs.image": "ubuntu"} {
+		if attr := diff.Attributes[name]; attr == nil || attr.New != expected {
+			t.Errorf("vra_deployment expected the drift of %s to be planned back to %s, actual %#v", name, expected, attr)
+		}
+	}
+	if attr := diff.Attributes["inputs.flavor"]; attr != nil {
+		t.Errorf("vra_deployment expected no changes for the server-side default of flavor, actual %#v", attr)
+	}
+
+	testResourceDestroy(t, r, state, m)
+}
+
+func TestResourceDeploymentFakeVRA_InputsValidation(t *testing.T) {
+	fake := newFakeVRA(t)
+	m := fake.client(t)
+	r := resourceDeployment()
+
+	catalogItemID := fake.addCatalogItem("catalog-item", map[string]interface{}{
+		"count":  map[string]interface{}{"type": "integer", "minimum": 1, "maximum": 5},
+		"flavor": map[string]interface{}{"type": "string", "enum": []interface{}{"small", "medium"}},
+		"image":  map[string]interface{}{"type": "string", "default": "ubuntu"},
+	}, "1")
+	fake.setRequiredInputs(catalogItemID, "count", "image")
+
+	var tests = []struct {
+		inputs  map[string]interface{}
+		path    cty.Path
+		message string
+	}{
+		{map[string]interface{}{"count": "2", "flavor": "small"}, nil, ""},
+		{map[string]interface{}{"count": "6"}, cty.GetAttrPath("inputs").IndexString("count"), `invalid input "count": must be at most 5`},
+		{map[string]interface{}{"flavor": "small"}, cty.GetAttrPath("inputs").IndexString("count"), `invalid input "count": is required`},
+		{map[string]interface{}{"count": "0", "flavor": "large"}, cty.GetAttrPath("inputs"), `invalid input "count": must be at least 1, invalid input "flavor": must be one of small, medium`},
+	}
+
+	for _, test := range tests {
+		config := map[string]interface{}{
+			"name":                 "deployment",
+			"project_id":           "project-id",
+			"catalog_item_id":      catalogItemID,
+			"catalog_item_version": "1",
+			"inputs":               test.inputs,
+		}
+		_, err := r.Diff(context.Background(), testResourceDiffState(t, r, nil, config), terraform.NewResourceConfigRaw(config), m)
+		if test.path == nil {
+			if err != nil {
+				t.Errorf("resourceDeploymentInputsCustomizeDiff expected no error for %v, actual %s", test.inputs, err)
+			}
+			continue
+		}
+
+		var pathErr cty.PathError
+		if !errors.As(err, &pathErr) {
+			t.Errorf("resourceDeploymentInputsCustomizeDiff expected a path error for %v, actual %#v", test.inputs, err)
+			continue
+		}
+		if !pathErr.Path.Equals(test.path) || err.Error() != test.message {
+			t.Errorf("resourceDeploymentInputsCustomizeDiff expected %q at %#v for %v, actual %q at %#v", test.message, test.path, test.inputs, err.Error(), pathErr.Path)
+		}
+	}
+
+	// The inputs not known during plan are validated when the deployment is requested
+	d := r.TestResourceData()
+	for key, value := range map[string]interface{}{
+		"name":                 "deployment",
+		"project_id":           "project-id",
+		"catalog_item_id":      catalogItemID,
+		"catalog_item_version": "1",
+		"inputs":               map[string]interface{}{"count": "0", "flavor": "large"},
+	} {
+		if err := d.Set(key, value); err != nil {
+			t.Fatalf("error setting %s: %s", key, err)
+		}
+	}
+	diags := r.CreateContext(context.Background(), d, m)
+	expected := map[string]string{
+		"count":  `invalid input "count": must be at least 1`,
+		"flavor": `invalid input "flavor": must be one of small, medium`,
+	}
+	if len(diags) != len(expected) {
+		t.Fatalf("resourceDeploymentCreate expected a diagnostic for each invalid input, actual %v", diags)
+	}
+	for _, diag := range diags {
+		var step cty.IndexStep
+		if len(diag.AttributePath) == 2 {
+			step, _ = diag.AttributePath[1].(cty.IndexStep)
+		}
+		if step.Key.IsNull() || step.Key.Type() != cty.String {
+			t.Errorf("resourceDeploymentCreate expected a diagnostic scoped to an input, actual %#v", diag.AttributePath)
+			continue
+		}
+		name := step.Key.AsString()
+		if !diag.AttributePath.Equals(cty.GetAttrPath("inputs").IndexString(name)) || diag.Summary != expected[name] {
+			t.Errorf("resourceDeploymentCreate expected %q at inputs[%q], actual %q at %#v", expected[name], name, diag.Summary, diag.AttributePath)
+		}
+	}
+	if len(fake.deployments) != 0 {
+		t.Errorf("resourceDeploymentCreate expected no deployment to be requested with invalid inputs, actual %d", len(fake.deployments))
+	}
+}
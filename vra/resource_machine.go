@@ -285,12 +285,12 @@ func resourceMachineCreate(ctx context.Context, d *schema.ResourceData, m interf
 	}
 
 	stateChangeFunc := retry.StateChangeConf{
-		Delay:      5 * time.Second,
+		Delay:      pollDelay,
 		Pending:    []string{models.RequestTrackerStatusINPROGRESS},
 		Refresh:    machineStateRefreshFunc(*apiClient, *createMachineCreated.Payload.ID),
 		Target:     []string{models.RequestTrackerStatusFINISHED},
 		Timeout:    d.Timeout(schema.TimeoutCreate),
-		MinTimeout: 5 * time.Second,
+		MinTimeout: pollMinTimeout,
 	}
 
 	resourceIDs, err := stateChangeFunc.WaitForStateContext(ctx)
@@ -315,12 +315,12 @@ func resourceMachineCreate(ctx context.Context, d *schema.ResourceData, m interf
 			}
 
 			stateChangeFunc := retry.StateChangeConf{
-				Delay:      5 * time.Second,
+				Delay:      pollDelay,
 				Pending:    []string{models.RequestTrackerStatusINPROGRESS},
 				Refresh:    machineStateRefreshFunc(*apiClient, *attachMachineDiskOk.Payload.ID),
 				Target:     []string{models.RequestTrackerStatusFINISHED},
 				Timeout:    d.Timeout(schema.TimeoutCreate),
-				MinTimeout: 5 * time.Second,
+				MinTimeout: pollMinTimeout,
 			}
 
 			if _, err := stateChangeFunc.WaitForStateContext(ctx); err != nil {
@@ -480,12 +480,12 @@ func attachAndDetachDisks(ctx context.Context, d *schema.ResourceData, apiClient
 		}
 
 		stateChangeFunc := retry.StateChangeConf{
-			Delay:      5 * time.Second,
+			Delay:      pollDelay,
 			Pending:    []string{models.RequestTrackerStatusINPROGRESS},
 			Refresh:    machineStateRefreshFunc(*apiClient, *deleteMachineDiskAccepted.Payload.ID),
 			Target:     []string{models.RequestTrackerStatusFINISHED},
 			Timeout:    d.Timeout(schema.TimeoutCreate),
-			MinTimeout: 5 * time.Second,
+			MinTimeout: pollMinTimeout,
 		}
 
 		if _, err := stateChangeFunc.WaitForStateContext(ctx); err != nil {
@@ -533,12 +533,12 @@ func attachAndDetachDisks(ctx context.Context, d *schema.ResourceData, apiClient
 			}
 
 			stateChangeFunc := retry.StateChangeConf{
-				Delay:      5 * time.Second,
+				Delay:      pollDelay,
 				Pending:    []string{models.RequestTrackerStatusINPROGRESS},
 				Refresh:    machineStateRefreshFunc(*apiClient, *attachMachineDiskOk.Payload.ID),
 				Target:     []string{models.RequestTrackerStatusFINISHED},
 				Timeout:    d.Timeout(schema.TimeoutCreate),
-				MinTimeout: 5 * time.Second,
+				MinTimeout: pollMinTimeout,
 			}
 
 			if _, err := stateChangeFunc.WaitForStateContext(ctx); err != nil {
@@ -604,12 +604,12 @@ func resizeMachine(ctx context.Context, d *schema.ResourceData, apiClient *clien
 		return err
 	}
 	stateChangeFunc := retry.StateChangeConf{
-		Delay:      5 * time.Second,
+		Delay:      pollDelay,
 		Pending:    []string{models.RequestTrackerStatusINPROGRESS},
 		Refresh:    machineStateRefreshFunc(*apiClient, *resizeMachine.Payload.ID),
 		Target:     []string{models.RequestTrackerStatusFINISHED},
 		Timeout:    d.Timeout(schema.TimeoutUpdate),
-		MinTimeout: 5 * time.Second,
+		MinTimeout: pollMinTimeout,
 	}
 	resourceIDs, err := stateChangeFunc.WaitForStateContext(ctx)
 	if err != nil {
@@ -632,12 +632,12 @@ func resourceMachineDelete(ctx context.Context, d *schema.ResourceData, m interf
 	}
 
 	stateChangeFunc := retry.StateChangeConf{
-		Delay:      5 * time.Second,
+		Delay:      pollDelay,
 		Pending:    []string{models.RequestTrackerStatusINPROGRESS},
 		Refresh:    machineStateRefreshFunc(*apiClient, *deleteMachine.Payload.ID),
 		Target:     []string{models.RequestTrackerStatusFINISHED},
 		Timeout:    d.Timeout(schema.TimeoutDelete),
-		MinTimeout: 5 * time.Second,
+		MinTimeout: pollMinTimeout,
 	}
 
 	if _, err = stateChangeFunc.WaitForStateContext(ctx); err != nil {
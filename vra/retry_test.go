@@ -0,0 +1,111 @@
+// © Broadcom. All Rights Reserved.
+// The term “Broadcom” refers to Broadcom Inc. and/or its subsidiaries.
+// SPDX-License-Identifier: MPL-2.0
+
+package vra
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryTransport(t *testing.T) {
+	var tests = []struct {
+		method        string
+		status        int
+		nonIdempotent bool
+		attempts      int32
+	}{
+		{http.MethodGet, http.StatusServiceUnavailable, false, 3},
+		{http.MethodGet, http.StatusTooManyRequests, false, 3},
+		{http.MethodGet, http.StatusNotFound, false, 1},
+		{http.MethodDelete, http.StatusBadGateway, false, 3},
+		{http.MethodPost, http.StatusServiceUnavailable, false, 1},
+		{http.MethodPost, http.StatusServiceUnavailable, true, 3},
+	}
+
+	for _, tt := range tests {
+		var attempts int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			if r.Method == http.MethodPost {
+				if body, _ := io.ReadAll(r.Body); string(body) != "{}" {
+					t.Errorf("retryTransport expected the request body to be replayed, actual %q", body)
+				}
+			}
+			w.WriteHeader(tt.status)
+		}))
+
+		client := &http.Client{Transport: newRetryTransport(http.DefaultTransport, RetryOptions{
+			MaxRetries:         2,
+			MinWait:            time.Millisecond,
+			MaxWait:            5 * time.Millisecond,
+			RetryNonIdempotent: tt.nonIdempotent,
+		})}
+
+		req, _ := http.NewRequest(tt.method, server.URL, strings.NewReader("{}"))
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Errorf("retryTransport returned error %s", err)
+		} else {
+			resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Errorf("retryTransport expected status %d, actual %d", tt.status, resp.StatusCode)
+			}
+		}
+
+		if attempts != tt.attempts {
+			t.Errorf("retryTransport %s with status %d expected %d attempts, actual %d", tt.method, tt.status, tt.attempts, attempts)
+		}
+		server.Close()
+	}
+}
+
+func TestRetryTransportBackoffRetryAfter(t *testing.T) {
+	transport := newRetryTransport(http.DefaultTransport, RetryOptions{
+		MaxRetries: 2,
+		MinWait:    time.Second,
+		MaxWait:    30 * time.Second,
+	}).(*retryTransport)
+
+	var tests = []struct {
+		retryAfter string
+		wait       time.Duration
+	}{
+		{"5", 5 * time.Second},
+		{"3600", 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		resp := &http.Response{Header: http.Header{"Retry-After": []string{tt.retryAfter}}}
+		if wait := transport.backoff(0, resp); wait != tt.wait {
+			t.Errorf("backoff with Retry-After %s expected %s, actual %s", tt.retryAfter, tt.wait, wait)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	var tests = []struct {
+		value string
+		wait  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"5", 5 * time.Second, true},
+		{"-1", 0, false},
+		{"invalid", 0, false},
+		{"Mon, 02 Jan 2006 15:04:05 GMT", 0, true},
+	}
+
+	for _, tt := range tests {
+		wait, ok := parseRetryAfter(tt.value)
+		if wait != tt.wait || ok != tt.ok {
+			t.Errorf("parseRetryAfter(%q) expected (%s, %t), actual (%s, %t)", tt.value, tt.wait, tt.ok, wait, ok)
+		}
+	}
+}
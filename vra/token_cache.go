@@ -0,0 +1,142 @@
+// © Broadcom. All Rights Reserved.
+// The term “Broadcom” refers to Broadcom Inc. and/or its subsidiaries.
+// SPDX-License-Identifier: MPL-2.0
+
+package vra
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// tokenCacheExpiryMargin is the time before expiry after which a cached access token is no longer reused
+const tokenCacheExpiryMargin = 5 * time.Minute
+
+// tokenCache persists access tokens on disk so that they can be reused across provider runs.
+// A nil tokenCache is valid and caches nothing.
+type tokenCache struct {
+	dir string
+}
+
+type cachedToken struct {
+	AccessToken string    `json:"access_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+func newTokenCache(dir string) *tokenCache {
+	if dir == "" {
+		return nil
+	}
+	return &tokenCache{dir: dir}
+}
+
+// path returns the cache file for the given url, organization and refresh token.
+// The refresh token is hashed so that it is never written to disk.
+func (c *tokenCache) path(url, organization, refreshToken string) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{url, organization, refreshToken}, "\n")))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// get returns the cached access token if it is not about to expire.
+func (c *tokenCache) get(url, organization, refreshToken string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+
+	data, err := os.ReadFile(c.path(url, organization, refreshToken))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Printf("[WARN] Unable to read the token cache: %s", err)
+		}
+		return "", false
+	}
+
+	var token cachedToken
+	if err := json.Unmarshal(data, &token); err != nil {
+		log.Printf("[WARN] Unable to decode the token cache: %s", err)
+		return "", false
+	}
+
+	if token.AccessToken == "" || time.Now().Add(tokenCacheExpiryMargin).After(token.ExpiresAt) {
+		log.Printf("[DEBUG] Cached access token is expired")
+		return "", false
+	}
+
+	log.Printf("[DEBUG] Reusing cached access token expiring at %s", token.ExpiresAt)
+	return token.AccessToken, true
+}
+
+// put stores the access token in the cache. Tokens without a known expiry are not cached.
+func (c *tokenCache) put(url, organization, refreshToken, accessToken string) {
+	if c == nil {
+		return
+	}
+
+	expiresAt, ok := tokenExpiry(accessToken)
+	if !ok {
+		log.Printf("[DEBUG] Access token has no expiry, skipping the token cache")
+		return
+	}
+
+	data, err := json.Marshal(cachedToken{AccessToken: accessToken, ExpiresAt: expiresAt})
+	if err != nil {
+		log.Printf("[WARN] Unable to encode the token cache: %s", err)
+		return
+	}
+
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		log.Printf("[WARN] Unable to create the token cache directory %s: %s", c.dir, err)
+		return
+	}
+
+	// Write to a temporary file first so that concurrent runs never read a partial file
+	file, err := os.CreateTemp(c.dir, ".token-*")
+	if err != nil {
+		log.Printf("[WARN] Unable to write the token cache: %s", err)
+		return
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		log.Printf("[WARN] Unable to write the token cache: %s", err)
+		return
+	}
+	if err := file.Close(); err != nil {
+		log.Printf("[WARN] Unable to write the token cache: %s", err)
+		return
+	}
+	if err := os.Rename(file.Name(), c.path(url, organization, refreshToken)); err != nil {
+		log.Printf("[WARN] Unable to write the token cache: %s", err)
+	}
+}
+
+// tokenExpiry returns the expiry of a JWT access token from its "exp" claim.
+func tokenExpiry(accessToken string) (time.Time, bool) {
+	parts := strings.Split(accessToken, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}, false
+	}
+
+	return time.Unix(claims.Exp, 0), true
+}
@@ -0,0 +1,70 @@
+// © Broadcom. All Rights Reserved.
+// The term “Broadcom” refers to Broadcom Inc. and/or its subsidiaries.
+// SPDX-License-Identifier: MPL-2.0
+
+package vra
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+func testAccessToken(expiresAt time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"exp":%d}`, expiresAt.Unix())))
+	return "eyJhbGciOiJub25lIn0." + payload + ".signature"
+}
+
+func TestTokenCache(t *testing.T) {
+	cache := newTokenCache(t.TempDir())
+	url, organization, refreshToken := "https://vra.example.com", "", "refresh-token"
+
+	if _, ok := cache.get(url, organization, refreshToken); ok {
+		t.Errorf("tokenCache returned a token from an empty cache")
+	}
+
+	validToken := testAccessToken(time.Now().Add(time.Hour))
+	cache.put(url, organization, refreshToken, validToken)
+	if token, ok := cache.get(url, organization, refreshToken); !ok || token != validToken {
+		t.Errorf("tokenCache expected token %s, actual %s", validToken, token)
+	}
+
+	if _, ok := cache.get(url, organization, "another-refresh-token"); ok {
+		t.Errorf("tokenCache returned a token cached for another refresh token")
+	}
+
+	cache.put(url, organization, refreshToken, testAccessToken(time.Now().Add(time.Minute)))
+	if _, ok := cache.get(url, organization, refreshToken); ok {
+		t.Errorf("tokenCache returned a token about to expire")
+	}
+
+	// A token without expiry is not cached, and does not replace the token already cached
+	cache.put(url, organization, refreshToken, validToken)
+	cache.put(url, organization, refreshToken, "opaque-token")
+	if token, ok := cache.get(url, organization, refreshToken); !ok || token != validToken {
+		t.Errorf("tokenCache expected token %s after caching a token without expiry, actual %s", validToken, token)
+	}
+}
+
+func TestTokenCacheWithoutExpiry(t *testing.T) {
+	dir := t.TempDir()
+	cache := newTokenCache(dir)
+	cache.put("https://vra.example.com", "", "refresh-token", "opaque-token")
+
+	if token, ok := cache.get("https://vra.example.com", "", "refresh-token"); ok {
+		t.Errorf("tokenCache returned token %s without expiry", token)
+	}
+	if entries, err := os.ReadDir(dir); err != nil || len(entries) != 0 {
+		t.Errorf("tokenCache expected no file for a token without expiry, actual %v (error %v)", entries, err)
+	}
+}
+
+func TestTokenCacheDisabled(t *testing.T) {
+	cache := newTokenCache("")
+	cache.put("https://vra.example.com", "", "refresh-token", testAccessToken(time.Now().Add(time.Hour)))
+	if _, ok := cache.get("https://vra.example.com", "", "refresh-token"); ok {
+		t.Errorf("disabled tokenCache returned a token")
+	}
+}